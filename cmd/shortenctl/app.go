@@ -0,0 +1,27 @@
+package main
+
+import (
+	"io"
+
+	"github.com/Tbits007/url-shortener/pkg/client"
+)
+
+type app struct {
+	client *client.Client
+	output string
+
+	stdin io.Reader
+	out   io.Writer
+}
+
+func newApp(opts options, stdin io.Reader, stdout io.Writer) *app {
+	return &app{
+		client: client.New(opts.server, client.Options{
+			User:     opts.user,
+			Password: opts.password,
+		}),
+		output: opts.output,
+		stdin:  stdin,
+		out:    stdout,
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Tbits007/url-shortener/pkg/client"
+)
+
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]command{
+	"create": create,
+	"get":    get,
+	"update": update,
+	"delete": remove,
+	"list":   list,
+	"stats":  stats,
+}
+
+// create [флаги] URL | create -f request.json
+func create(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "create", "[flags] URL")
+	req, file := linkFlags(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *file != "" {
+		if fs.NFlag() > 1 {
+			return usagef("create: -f cannot be combined with other flags")
+		}
+		if req, _, err = a.readRequest(*file); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case len(pos) == 1:
+		req.URL = pos[0]
+	case len(pos) > 1:
+		return usagef("create: expected one URL, got %d arguments", len(pos))
+	case req.URL == "":
+		return usagef("create: URL is required")
+	}
+
+	created, err := a.client.Create(ctx, *req)
+	if err != nil {
+		return err
+	}
+
+	// Ответ на создание содержит только алиас, а показать нужно ссылку целиком
+	link, err := a.client.Get(ctx, created.Alias)
+	if err != nil {
+		return err
+	}
+
+	return a.printLink(link)
+}
+
+func get(ctx context.Context, a *app, args []string) error {
+	alias, err := aliasArg(a, "get", args)
+	if err != nil {
+		return err
+	}
+
+	link, err := a.client.Get(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	return a.printLink(link)
+}
+
+// update [флаги] ALIAS меняет только поля из заданных флагов. Пустое
+// значение флага сбрасывает поле: -title "" убирает название.
+func update(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "update", "[flags] ALIAS")
+	req, file := linkFlags(fs)
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usagef("update: expected ALIAS")
+	}
+
+	var fields []string
+	if *file != "" {
+		if fs.NFlag() > 1 {
+			return usagef("update: -f cannot be combined with other flags")
+		}
+		if req, fields, err = a.readRequest(*file); err != nil {
+			return err
+		}
+	} else {
+		fs.Visit(func(f *flag.Flag) {
+			if field := fieldOf(f.Name); !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		})
+	}
+	if len(fields) == 0 {
+		return usagef("update: nothing to update, set at least one field flag")
+	}
+
+	link, err := a.client.Update(ctx, pos[0], *req, fields...)
+	if err != nil {
+		return err
+	}
+
+	return a.printLink(link)
+}
+
+func remove(ctx context.Context, a *app, args []string) error {
+	alias, err := aliasArg(a, "delete", args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.Delete(ctx, alias); err != nil {
+		return err
+	}
+
+	a.printf("link %s deleted\n", alias)
+
+	return nil
+}
+
+func list(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "list", "[flags]")
+	var opts client.ListOptions
+	fs.StringVar(&opts.Owner, "owner", "", "только ссылки этого владельца")
+	fs.IntVar(&opts.PageSize, "limit", 0, "сколько ссылок показать; по умолчанию - как решит сервер")
+	fs.StringVar(&opts.PageToken, "page-token", "", "токен следующей страницы из предыдущего вывода")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return usagef("list: unexpected arguments")
+	}
+	if opts.PageSize < 0 {
+		return usagef("list: -limit must not be negative")
+	}
+
+	page, err := a.client.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return a.printLinks(page)
+}
+
+func stats(ctx context.Context, a *app, args []string) error {
+	alias, err := aliasArg(a, "stats", args)
+	if err != nil {
+		return err
+	}
+
+	st, err := a.client.Stats(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	return a.printStats(st)
+}
+
+// linkFlags - флаги полей ссылки, общие для create и update. Имена флагов -
+// имена полей JSON через дефис; utm-* и og-* - поля объектов utm и og.
+func linkFlags(fs *flag.FlagSet) (*client.CreateRequest, *string) {
+	req := &client.CreateRequest{}
+
+	fs.StringVar(&req.URL, "url", "", "адрес назначения")
+	fs.StringVar(&req.Alias, "alias", "", "алиас; при создании пустой - сгенерировать")
+	fs.StringVar(&req.Title, "title", "", "название ссылки")
+	fs.IntVar(&req.RedirectType, "redirect-type", 0, "код редиректа: 301, 302, 303, 307 или 308")
+	fs.BoolVar(&req.ForwardQuery, "forward-query", false, "переносить query-параметры запроса")
+	fs.StringVar(&req.QueryConflict, "query-conflict", "", "keep, override или append")
+	fs.BoolVar(&req.ForwardPath, "forward-path", false, "дописывать остаток пути")
+	fs.StringVar(&req.UTM.Source, "utm-source", "", "utm_source")
+	fs.StringVar(&req.UTM.Medium, "utm-medium", "", "utm_medium")
+	fs.StringVar(&req.UTM.Campaign, "utm-campaign", "", "utm_campaign")
+	fs.StringVar(&req.UTM.Term, "utm-term", "", "utm_term")
+	fs.StringVar(&req.UTM.Content, "utm-content", "", "utm_content")
+	fs.StringVar(&req.UTMTemplate, "utm-template", "", "имя шаблона UTM-меток")
+	fs.StringVar(&req.Password, "password", "", "пароль для открытия ссылки")
+	fs.IntVar(&req.MaxClicks, "max-clicks", 0, "сколько раз ссылку можно открыть")
+	fs.Func("active-from", "начало окна активности, RFC 3339", timeFlag(&req.ActiveFrom))
+	fs.Func("active-until", "конец окна активности, RFC 3339", timeFlag(&req.ActiveUntil))
+	fs.StringVar(&req.PendingURL, "pending-url", "", "адрес до начала окна")
+	fs.StringVar(&req.ExpiredURL, "expired-url", "", "адрес после конца окна")
+	fs.BoolVar(&req.Interstitial, "interstitial", false, "показывать страницу перехода")
+	fs.StringVar(&req.OG.Title, "og-title", "", "заголовок превью")
+	fs.StringVar(&req.OG.Description, "og-description", "", "описание превью")
+	fs.StringVar(&req.OG.Image, "og-image", "", "картинка превью")
+
+	file := fs.String("f", "", "JSON-файл запроса с любыми полями API, - для stdin")
+
+	return req, file
+}
+
+// fieldOf переводит имя флага в имя поля JSON.
+func fieldOf(name string) string {
+	switch {
+	case strings.HasPrefix(name, "utm-") && name != "utm-template":
+		return "utm"
+	case strings.HasPrefix(name, "og-"):
+		return "og"
+	}
+
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func timeFlag(dst **time.Time) func(string) error {
+	return func(s string) error {
+		if s == "" {
+			*dst = nil
+			return nil
+		}
+
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		*dst = &t
+
+		return nil
+	}
+}
+
+// readRequest читает запрос из JSON-файла и возвращает его вместе
+// со списком заданных в файле полей.
+func (a *app) readRequest(path string) (*client.CreateRequest, []string, error) {
+	var r io.Reader = a.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var req client.CreateRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return &req, names, nil
+}
+
+func aliasArg(a *app, name string, args []string) (string, error) {
+	fs := newFlagSet(a, name, "ALIAS")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(pos) != 1 {
+		return "", usagef("%s: expected ALIAS", name)
+	}
+
+	return pos[0], nil
+}
+
+func newFlagSet(a *app, name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: shortenctl %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseArgs разбирает флаги и возвращает позиционные аргументы. В отличие
+// от fs.Parse флаги можно писать и после аргументов: create URL -alias docs.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
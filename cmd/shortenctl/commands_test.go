@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/http-server/router"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	mockStorage.On("SaveURL", mock.Anything, mock.MatchedBy(func(l storage.Link) bool {
+		return l.URL == "https://example.com/docs" && l.Alias == "docs" &&
+			l.UTM.Source == "cli" && l.Owner == "admin"
+	})).Return(nil).Once()
+	mockStorage.On("GetURL", mock.Anything, "docs").
+		Return(storage.Link{Alias: "docs", URL: "https://example.com/docs", Owner: "admin"}, nil).Once()
+
+	a, out := newTestApp(t, mockStorage, outputTable)
+
+	// Флаги можно писать и после URL
+	err := create(context.Background(), a, []string{"https://example.com/docs", "-alias", "docs", "-utm-source", "cli"})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "alias  docs")
+	assert.Contains(t, out.String(), "owner  admin")
+	assert.NotContains(t, out.String(), "status")
+}
+
+func TestUpdate(t *testing.T) {
+	current := storage.Link{
+		Alias: "docs",
+		URL:   "https://example.com/docs",
+		Title: "Docs",
+		UTM:   storage.UTM{Source: "newsletter"},
+	}
+
+	mockStorage := router.NewMockStorage(t)
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(current, nil).Twice()
+	mockStorage.On("UpdateURL", mock.Anything, mock.MatchedBy(func(l storage.Link) bool {
+		// Пустой флаг сбрасывает поле, метки utm сливаются с текущими
+		return l.Title == "" && l.MaxClicks == 5 && l.URL == current.URL &&
+			l.UTM == storage.UTM{Source: "newsletter", Campaign: "launch"}
+	})).Return(nil).Once()
+
+	a, out := newTestApp(t, mockStorage, outputJSON)
+
+	err := update(context.Background(), a, []string{"-title", "", "-max-clicks", "5", "-utm-campaign", "launch", "docs"})
+	require.NoError(t, err)
+
+	var link map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &link))
+	assert.Equal(t, "docs", link["alias"])
+}
+
+func TestUpdate_NothingToUpdate(t *testing.T) {
+	a, _ := newTestApp(t, router.NewMockStorage(t), outputTable)
+
+	err := update(context.Background(), a, []string{"docs"})
+
+	var usageErr usageError
+	assert.True(t, errors.As(err, &usageErr))
+}
+
+func TestList(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", Limit: 2}).Return([]storage.Link{
+		{Alias: "a", URL: "https://example.com/a", Owner: "admin"},
+		{Alias: "b", URL: "https://example.com/b", Owner: "admin"},
+	}, nil).Once()
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", After: "a", Limit: 2}).Return([]storage.Link{
+		{Alias: "b", URL: "https://example.com/b", Owner: "admin"},
+	}, nil).Once()
+
+	a, out := newTestApp(t, mockStorage, outputTable)
+
+	require.NoError(t, list(context.Background(), a, []string{"-owner", "admin", "-limit", "1"}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "ALIAS"))
+	assert.True(t, strings.HasPrefix(lines[1], "a "))
+	token, ok := strings.CutPrefix(lines[3], "next page: -page-token ")
+	require.True(t, ok, lines[3])
+
+	out.Reset()
+	a.output = outputJSON
+	require.NoError(t, list(context.Background(), a, []string{"-owner", "admin", "-limit", "1", "-page-token", token}))
+
+	var page struct {
+		Links         []map[string]any `json:"links"`
+		NextPageToken string           `json:"next_page_token"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &page))
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, "b", page.Links[0]["alias"])
+	assert.Empty(t, page.NextPageToken)
+}
+
+func TestList_NegativeLimit(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	// 0 - размер страницы по умолчанию: сервер берет 50 и запрашивает на одну больше
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Limit: 51}).Return(nil, nil).Once()
+
+	a, _ := newTestApp(t, mockStorage, outputTable)
+
+	err := list(context.Background(), a, []string{"-limit", "-1"})
+
+	var usageErr usageError
+	require.True(t, errors.As(err, &usageErr))
+	assert.Contains(t, err.Error(), "must not be negative")
+
+	assert.NoError(t, list(context.Background(), a, []string{"-limit", "0"}))
+}
+
+func TestStatsAndDelete(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{
+		Alias:    "docs",
+		Variants: []storage.Variant{{Name: "a", URL: "https://example.com/a", Weight: 1}},
+	}, nil).Once()
+	mockStorage.On("GetClickStats", mock.Anything, "docs").
+		Return(storage.ClickStats{Total: 4, Variants: map[string]int{"a": 4}}, nil).Once()
+	mockStorage.On("DeleteURL", mock.Anything, "docs").Return(nil).Once()
+	mockStorage.On("DeleteURL", mock.Anything, "missing").Return(storage.ErrURLNotFound).Once()
+
+	a, out := newTestApp(t, mockStorage, outputTable)
+
+	require.NoError(t, stats(context.Background(), a, []string{"docs"}))
+	assert.Contains(t, out.String(), "clicks  4")
+	assert.Contains(t, out.String(), "VARIANT")
+
+	require.NoError(t, remove(context.Background(), a, []string{"docs"}))
+	assert.Contains(t, out.String(), "link docs deleted")
+
+	assert.ErrorIs(t, remove(context.Background(), a, []string{"missing"}), storage.ErrURLNotFound)
+}
+
+func TestRun_Usage(t *testing.T) {
+	var out bytes.Buffer
+
+	var usageErr usageError
+	assert.True(t, errors.As(run(context.Background(), []string{"rename"}, nil, &out), &usageErr))
+	assert.True(t, errors.As(run(context.Background(), []string{"-o", "yaml", "get", "docs"}, nil, &out), &usageErr))
+	assert.True(t, errors.As(run(context.Background(), nil, nil, &out), &usageErr))
+}
+
+// newTestApp - приложение, которое ходит в роутер сервиса поверх мока хранилища.
+func newTestApp(t *testing.T, st router.Storage, output string) (*app, *bytes.Buffer) {
+	t.Helper()
+
+	cfg := &config.Config{
+		HTTPServer: config.HTTPServer{
+			User:     "admin",
+			Password: "secret",
+		},
+	}
+
+	srv := httptest.NewServer(router.New(slogdiscard.NewDiscardLogger(), cfg, router.Deps{
+		Storage:      st,
+		ShuttingDown: new(atomic.Bool),
+	}))
+	t.Cleanup(srv.Close)
+
+	var out bytes.Buffer
+
+	return newApp(options{
+		server:   srv.URL,
+		user:     "admin",
+		password: "secret",
+		output:   output,
+	}, nil, &out), &out
+}
@@ -0,0 +1,100 @@
+// shortenctl - консольный клиент сервиса: создает, показывает, меняет
+// и удаляет ссылки без curl и ручного JSON.
+//
+//	shortenctl [-server URL] [-user U] [-password P] [-o table|json] <команда> [флаги] [аргументы]
+//
+// Команды идут в запущенный сервер через HTTP API (pkg/client). Прямой
+// работы с хранилищем нет намеренно, см. README.md.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "shortenctl:", err)
+		os.Exit(1)
+	}
+}
+
+// usageError - неверный вызов команды, а не ошибка сервиса.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("shortenctl", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+
+	var opts options
+	fs.StringVar(&opts.server, "server", envOr("SHORTENER_URL", "http://localhost:8080"), "адрес сервиса (SHORTENER_URL)")
+	fs.StringVar(&opts.user, "user", os.Getenv("SHORTENER_USER"), "логин basic auth (SHORTENER_USER)")
+	fs.StringVar(&opts.password, "password", os.Getenv("SHORTENER_PASSWORD"), "пароль basic auth (SHORTENER_PASSWORD)")
+	fs.StringVar(&opts.output, "o", outputTable, "формат вывода: table или json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: shortenctl [flags] create|get|update|delete|list|stats [flags] [args]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.output != outputTable && opts.output != outputJSON {
+		return usagef("-o must be %s or %s", outputTable, outputJSON)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return usagef("command is required")
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		return usagef("unknown command %q", fs.Arg(0))
+	}
+
+	return cmd(ctx, newApp(opts, stdin, stdout), fs.Args()[1:])
+}
+
+type options struct {
+	server   string
+	user     string
+	password string
+	output   string
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Tbits007/url-shortener/pkg/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func (a *app) printJSON(v any) error {
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// printLink выводит ссылку таблицей "поле - значение" в порядке полей
+// ответа API. Пустые поля API не отдает, поэтому их нет и в таблице.
+func (a *app) printLink(link client.Link) error {
+	if a.output == outputJSON {
+		return a.printJSON(link)
+	}
+
+	rows, err := objectRows(link)
+	if err != nil {
+		return err
+	}
+
+	tw := newTable(a.out)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+	}
+
+	return tw.Flush()
+}
+
+// printLinks выводит страницу списка. Если есть следующая страница,
+// под таблицей печатается ее токен для -page-token.
+func (a *app) printLinks(page client.LinkList) error {
+	if a.output == outputJSON {
+		return a.printJSON(struct {
+			Links         []client.Link `json:"links"`
+			NextPageToken string        `json:"next_page_token,omitempty"`
+		}{page.Links, page.NextPageToken})
+	}
+
+	tw := newTable(a.out)
+	fmt.Fprintln(tw, "ALIAS\tURL\tOWNER\tCREATED")
+	for _, link := range page.Links {
+		var created string
+		if link.CreatedAt != nil {
+			created = link.CreatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", link.Alias, link.URL, link.Owner, created)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if page.NextPageToken != "" {
+		_, err := fmt.Fprintf(a.out, "\nnext page: -page-token %s\n", page.NextPageToken)
+		return err
+	}
+
+	return nil
+}
+
+func (a *app) printStats(st client.Stats) error {
+	if a.output == outputJSON {
+		return a.printJSON(st)
+	}
+
+	tw := newTable(a.out)
+	fmt.Fprintf(tw, "alias\t%s\n", st.Alias)
+	fmt.Fprintf(tw, "clicks\t%d\n", st.Clicks)
+
+	if len(st.Variants) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "VARIANT\tWEIGHT\tCLICKS\tURL")
+		for _, v := range st.Variants {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", v.Name, v.Weight, v.Clicks, v.URL)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (a *app) printf(format string, args ...any) {
+	if a.output == outputTable {
+		fmt.Fprintf(a.out, format, args...)
+	}
+}
+
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+}
+
+// objectRows раскладывает JSON-объект v на пары "ключ - значение"
+// с сохранением порядка полей. Вложенные объекты и списки остаются
+// компактным JSON, поле status ответа пропускается.
+func objectRows(v any) ([][2]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var rows [][2]string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if key == "status" {
+			continue
+		}
+
+		value := string(raw)
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			value = s
+		}
+		rows = append(rows, [2]string{key, value})
+	}
+
+	return rows, nil
+}
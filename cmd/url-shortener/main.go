@@ -1,19 +1,27 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/Tbits007/url-shortener/internal/config"
-	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/redirect"
-	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
-	"github.com/Tbits007/url-shortener/internal/http-server/middleware/logger"
+	grpcserver "github.com/Tbits007/url-shortener/internal/grpc-server"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/health"
+	"github.com/Tbits007/url-shortener/internal/http-server/router"
+	"github.com/Tbits007/url-shortener/internal/lib/geo"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
 	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
 	"github.com/Tbits007/url-shortener/internal/storage/postgres"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 const (
@@ -34,51 +42,138 @@ func main() {
     log.Info("initializing server", slog.String("address", cfg.HTTPServer.Address))
     log.Debug("logger debug mode enabled")
 
+	// Tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Error("failed to init tracing", sl.Err(err))
+		os.Exit(1)
+	}
+
 	// Storage
-	connStr := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Postgres.User,
-        cfg.Postgres.Password,
-        cfg.Postgres.Host,
-        cfg.Postgres.Port,
-        cfg.Postgres.DBName,
-	)
-	storage, err := postgres.New(connStr)
+	storage, err := postgres.New(context.Background(), cfg.Postgres)
 	if err != nil {
 		log.Error("failed to init storage", sl.Err(err))
 		os.Exit(1)
 	}
 
-	router := chi.NewRouter()
+	metrics.RegisterDBStats(storage.PoolStats)
 
-	router.Use(middleware.RequestID) // Добавляет request_id в каждый запрос, для трейсинга
-	router.Use(logger.New(log)) // Логирование всех запросов
-	router.Use(middleware.Recoverer)  // Если где-то внутри сервера (обработчика запроса) произойдет паника, приложение не должно упасть
-	router.Use(middleware.URLFormat) // Парсер URLов поступающих запросов
+	// Выставляется при остановке, чтобы /readyz вывел инстанс из ротации
+	var shuttingDown atomic.Bool
 
-	router.Group(func(r chi.Router) {
-		r.Use(middleware.BasicAuth("url-shortener", map[string]string{
-			cfg.HTTPServer.User: cfg.HTTPServer.Password,
-		}))		
+	passwordGuard, err := linkpassword.NewGuard(cfg.Redirect.Password)
+	if err != nil {
+		log.Error("failed to init link password guard", sl.Err(err))
+		os.Exit(1)
+	}
+	if cfg.Redirect.Password.CookieSecret == "" {
+		log.Warn("redirect.password.cookie_secret is not set, link access cookies will not survive restart")
+	}
+
+	geoResolver, err := geo.New(cfg.Redirect.Geo)
+	if err != nil {
+		log.Error("failed to init geoip", sl.Err(err))
+		os.Exit(1)
+	}
+	defer geoResolver.Close()
+
+	htmlPages, err := pages.New(cfg.Pages)
+	if err != nil {
+		log.Error("failed to load page templates", sl.Err(err))
+		os.Exit(1)
+	}
 
-		router.Post("/saveURL", save.New(log, storage))
+	// Все маршруты сервиса собраны в router.New
+	handler := router.New(log, cfg, router.Deps{
+		Storage:      storage,
+		Guard:        passwordGuard,
+		Countries:    geoResolver,
+		Pages:        htmlPages,
+		ShuttingDown: &shuttingDown,
 	})
 
-	router.Get("/{alias}", redirect.New(log, storage))
-	
 	srv := &http.Server{
 		Addr: cfg.HTTPServer.Address,
-		Handler: router,
+		Handler: handler,
 		ReadTimeout: cfg.HTTPServer.Timeout,
 		WriteTimeout: cfg.HTTPServer.Timeout,
 		IdleTimeout: cfg.HTTPServer.IdleTimeout,
 	}
 
-	if err = srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", sl.Err(err))
+			stop()
+		}
+	}()
+
+	log.Info("server started")
+
+	// gRPC API на своем порту, поверх того же хранилища
+	var grpcSrv *grpcserver.Server
+	if cfg.GRPCServer.Address != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCServer.Address)
+		if err != nil {
+			log.Error("failed to listen grpc address", sl.Err(err))
+			os.Exit(1)
+		}
+
+		// Сервис здоровья gRPC проверяет то же, что /readyz
+		grpcSrv = grpcserver.New(log, map[string]string{
+			cfg.HTTPServer.User: cfg.HTTPServer.Password,
+		}, storage, grpcserver.Readiness{
+			Check: func(ctx context.Context) error {
+				return health.Ready(ctx, storage, &shuttingDown)
+			},
+			Interval: cfg.Health.GRPCCheckInterval,
+			Timeout:  cfg.Health.ReadinessTimeout,
+		})
+
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Error("failed to start grpc server", sl.Err(err))
+				stop()
+			}
+		}()
+
+		log.Info("grpc server started", slog.String("address", cfg.GRPCServer.Address))
+	}
+
+	<-ctx.Done()
+
+	// Сначала перестаем отвечать готовностью, чтобы балансировщик
+	// убрал инстанс из ротации, затем дожидаемся текущих запросов
+	log.Info("stopping server")
+	shuttingDown.Store(true)
+	if grpcSrv != nil {
+		grpcSrv.Drain()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to stop server", sl.Err(err))
+	}
+
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to stop grpc server", sl.Err(err))
+		}
+	}
+
+	if err := storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush traces", sl.Err(err))
 	}
-	log.Error("server stopped")
 
+	log.Info("server stopped")
 }
 
 func setupLogger(env string) *slog.Logger {
@@ -2,8 +2,14 @@ package config
 
 import (
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
@@ -11,24 +17,348 @@ import (
 type Config struct {
 	Env         string     `yaml:"env" env-default:"dev"`
 	HTTPServer  HTTPServer `yaml:"http_server"`
+	GRPCServer  GRPCServer `yaml:"grpc_server"`
 	Postgres    Postgres   `yaml:"postgres"`
+	Health      Health     `yaml:"health"`
+	Tracing     Tracing    `yaml:"tracing"`
+	Redirect    Redirect   `yaml:"redirect"`
+	Pages       Pages      `yaml:"pages"`
+	API         API        `yaml:"api"`
 }
 
 type HTTPServer struct {
 	Address     string        `yaml:"address" env-default:"localhost:8080"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
+	// Публичный адрес сервиса, от которого строятся короткие ссылки
+	// (https://sho.rt). Не задан - берется из заголовка Host запроса
+	BaseURL string `yaml:"base_url"`
+	// Отдавать страницу Swagger UI на /openapi/ui. Сама спецификация
+	// доступна на /openapi.json всегда
+	SwaggerUI bool `yaml:"swagger_ui"`
 
 	User        string        `yaml:"user" env-required:"true"`
     Password    string        `yaml:"password" env-required:"true"`
 }
 
+// GRPCServer - gRPC API на отдельном порту. Логин и пароль общие с HTTPServer.
+type GRPCServer struct {
+	// Адрес для gRPC, например localhost:9090. Не задан - gRPC выключен
+	Address string `yaml:"address"`
+}
+
+// API - жизненный цикл старых маршрутов вне /api/v1 (/saveURL, /url/...,
+// /utm-templates/...). Они работают как прежде, но сообщают клиентам
+// о замене заголовками Deprecation и Sunset.
+type API struct {
+	LegacyDeprecatedAt time.Time `yaml:"legacy_deprecated_at" env-layout:"2006-01-02" env-default:"2026-10-19"`
+	// Дата, после которой старые маршруты могут быть удалены
+	LegacySunset time.Time `yaml:"legacy_sunset" env-layout:"2006-01-02" env-default:"2027-04-30"`
+}
+
 type Postgres struct {
+	// DSN заменяет хост, порт, логин, пароль и имя базы. Настройки TLS
+	// и сессии ниже дописываются к нему, если в DSN их нет
+	DSN      string `yaml:"dsn"`
 	Host     string `yaml:"host" env-default:"localhost"`
 	Port     int    `yaml:"port" env-default:"5432"`
 	User     string `yaml:"user" env-default:"postgres"`
 	Password string `yaml:"password" env-default:"postgres"`
 	DBName   string `yaml:"dbname" env-default:"postgres"`
+
+	SSLMode     string `yaml:"sslmode" env-default:"disable"`
+	SSLRootCert string `yaml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert"`
+	SSLKey      string `yaml:"sslkey"`
+
+	ApplicationName  string        `yaml:"application_name" env-default:"url-shortener"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env-default:"5s"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env-default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env-default:"25"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env-default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env-default:"5m"`
+
+	// Сколько раз пытаться достучаться до базы при старте, прежде чем сдаться
+	ConnectAttempts     int           `yaml:"connect_attempts" env-default:"5"`
+	ConnectRetryBackoff time.Duration `yaml:"connect_retry_backoff" env-default:"1s"`
+
+	QueryTimeouts QueryTimeouts `yaml:"query_timeouts"`
+
+	// Строки подключения к репликам. Через них обслуживаются редиректы;
+	// если реплик нет или все недоступны, чтения идут в primary.
+	Replicas             []string      `yaml:"replicas"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env-default:"5s"`
+	// Сколько после записи ссылки или шаблона их чтение идет в primary,
+	// а не в реплику. Покрывает отставание репликации для записей,
+	// сделанных этим инстансом.
+	ReplicaLagWindow time.Duration `yaml:"replica_lag_window" env-default:"5s"`
+
+	// Запись переходов. Редирект кладет событие в очередь и не ждет базу,
+	// события пишутся пачками не больше 1000 штук. Если очередь полна,
+	// новые события теряются.
+	ClickQueueSize     int           `yaml:"click_queue_size" env-default:"10000"`
+	ClickBatchSize     int           `yaml:"click_batch_size" env-default:"100"`
+	ClickFlushInterval time.Duration `yaml:"click_flush_interval" env-default:"1s"`
+}
+
+// ConnString возвращает строку подключения к Postgres. Логин и пароль
+// экранируются, поэтому спецсимволы в пароле не ломают URL. Параметры,
+// заданные в DSN явно, важнее настроек конфига.
+func (p Postgres) ConnString() string {
+	if p.DSN != "" {
+		return withParams(p.DSN, p.sessionParams())
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.User, p.Password),
+		Host:     net.JoinHostPort(p.Host, strconv.Itoa(p.Port)),
+		Path:     "/" + p.DBName,
+		RawQuery: p.sessionParams().Encode(),
+	}
+
+	return u.String()
+}
+
+// ReplicaConnString дополняет строку подключения реплики настройками TLS
+// и сессии primary (sslmode, application_name, statement_timeout...),
+// которые в ней не заданы явно.
+func (p Postgres) ReplicaConnString(dsn string) string {
+	return withParams(dsn, p.sessionParams())
+}
+
+// sessionParams - параметры подключения, общие для DSN, primary и реплик.
+func (p Postgres) sessionParams() url.Values {
+	query := url.Values{}
+	if p.SSLMode != "" {
+		query.Set("sslmode", p.SSLMode)
+	}
+	if p.SSLRootCert != "" {
+		query.Set("sslrootcert", p.SSLRootCert)
+	}
+	if p.SSLCert != "" {
+		query.Set("sslcert", p.SSLCert)
+	}
+	if p.SSLKey != "" {
+		query.Set("sslkey", p.SSLKey)
+	}
+	if p.ApplicationName != "" {
+		query.Set("application_name", p.ApplicationName)
+	}
+	if p.StatementTimeout > 0 {
+		// Неизвестные драйверу параметры lib/pq передает серверу как настройки сессии
+		query.Set("statement_timeout", strconv.FormatInt(p.StatementTimeout.Milliseconds(), 10))
+	}
+
+	return query
+}
+
+// withParams дописывает к DSN параметры, которых в нем нет. Заданные
+// в DSN значения не меняются. Понимает оба формата lib/pq: URL
+// (postgres://...) и строку "ключ=значение".
+func withParams(dsn string, params url.Values) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// Некорректный DSN отклонит драйвер с понятной ошибкой
+			return dsn
+		}
+
+		existing := u.Query()
+		missing := url.Values{}
+		for key := range params {
+			if !existing.Has(key) {
+				missing.Set(key, params.Get(key))
+			}
+		}
+		if len(missing) == 0 {
+			return dsn
+		}
+
+		// Дописываем к исходной строке, а не собираем URL заново,
+		// чтобы не менять экранирование логина и пароля
+		sep := "&"
+		switch {
+		case strings.HasSuffix(dsn, "?") || strings.HasSuffix(dsn, "&"):
+			sep = ""
+		case u.RawQuery == "":
+			sep = "?"
+		}
+
+		return dsn + sep + missing.Encode()
+	}
+
+	existing := dsnKeys(dsn)
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if !existing[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, key := range keys {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(params.Get(key))
+		b.WriteString(key + "='" + value + "'")
+	}
+
+	return b.String()
+}
+
+// dsnKeys возвращает ключи строки подключения "ключ=значение".
+// Значения могут быть в одинарных кавычках с экранированием через \.
+func dsnKeys(dsn string) map[string]bool {
+	keys := map[string]bool{}
+
+	i := 0
+	for i < len(dsn) {
+		for i < len(dsn) && dsn[i] == ' ' {
+			i++
+		}
+
+		start := i
+		for i < len(dsn) && dsn[i] != '=' && dsn[i] != ' ' {
+			i++
+		}
+		key := dsn[start:i]
+
+		for i < len(dsn) && dsn[i] == ' ' {
+			i++
+		}
+		if i >= len(dsn) || dsn[i] != '=' {
+			break
+		}
+		i++
+		for i < len(dsn) && dsn[i] == ' ' {
+			i++
+		}
+
+		if i < len(dsn) && dsn[i] == '\'' {
+			for i++; i < len(dsn) && dsn[i] != '\''; i++ {
+				if dsn[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		} else {
+			for i < len(dsn) && dsn[i] != ' ' {
+				i++
+			}
+		}
+
+		if key != "" {
+			keys[key] = true
+		}
+	}
+
+	return keys
+}
+
+// QueryTimeouts ограничивают время выполнения запросов к базе,
+// отдельно для чтения (редиректы) и записи (создание ссылок).
+type QueryTimeouts struct {
+	Read  time.Duration `yaml:"read" env-default:"1s"`
+	Write time.Duration `yaml:"write" env-default:"3s"`
+}
+
+type Health struct {
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" env-default:"1s"`
+	// Как часто сервис здоровья gRPC повторяет проверки /readyz
+	GRPCCheckInterval time.Duration `yaml:"grpc_check_interval" env-default:"5s"`
+}
+
+type Tracing struct {
+	// none - трейсинг выключен, otlp - отправка в коллектор, stdout - вывод в консоль для разработки
+	Exporter    string  `yaml:"exporter" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env-default:"url-shortener"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+type Redirect struct {
+	// Код редиректа для ссылок, у которых он не задан явно
+	DefaultStatus int `yaml:"default_status" env-default:"302"`
+	// Сколько браузерам и CDN разрешено кэшировать постоянные редиректы (301, 308)
+	PermanentCacheMaxAge time.Duration `yaml:"permanent_cache_max_age" env-default:"24h"`
+
+	Password LinkPassword `yaml:"password"`
+
+	// Ответ для ссылок, окно активности которых еще не началось,
+	// если у ссылки не задан pending_url
+	NotYetAvailableStatus  int    `yaml:"not_yet_available_status" env-default:"404"`
+	NotYetAvailableMessage string `yaml:"not_yet_available_message" env-default:"link is not yet available"`
+
+	Geo Geo `yaml:"geo"`
+
+	// Сколько посетитель помнит выданный ему вариант A/B-теста
+	VariantCookieTTL time.Duration `yaml:"variant_cookie_ttl" env-default:"720h"`
+
+	Interstitial Interstitial `yaml:"interstitial"`
+
+	// Куда отправлять посетителей несуществующих алиасов, например на главную.
+	// Не задан - отдается 404
+	NotFoundURL string `yaml:"not_found_url"`
+}
+
+// Interstitial - когда вместо редиректа показывать страницу
+// "вы переходите на ...". Ссылка может включить ее и для себя.
+// Доменов у сервиса один, поэтому настройки общие для всех ссылок.
+type Interstitial struct {
+	// Предупреждать о переходе на чужие сайты: все хосты, кроме InternalHosts
+	// и их поддоменов
+	External      bool     `yaml:"external"`
+	InternalHosts []string `yaml:"internal_hosts"`
+	// Предупреждать о переходе по ссылкам моложе этого срока. 0 - не предупреждать
+	NewLinkAge time.Duration `yaml:"new_link_age"`
+}
+
+// Pages - пути к своим шаблонам HTML-страниц (html/template).
+// Пустой путь - встроенный шаблон.
+type Pages struct {
+	PreviewTemplate      string `yaml:"preview_template"`
+	InterstitialTemplate string `yaml:"interstitial_template"`
+	OpenGraphTemplate    string `yaml:"open_graph_template"`
+	NotFoundTemplate     string `yaml:"not_found_template"`
+	GoneTemplate         string `yaml:"gone_template"`
+}
+
+// Geo - определение страны посетителя для гео-таргетинга.
+// Внешние сервисы не используются: страна берется из заголовка доверенного
+// прокси, а если его нет - из локального файла базы GeoIP.
+type Geo struct {
+	// Файл базы в формате MaxMind MMDB (GeoLite2-Country, GeoLite2-City, DB-IP Lite).
+	// Не задан - страна по IP не определяется
+	DatabasePath string `yaml:"database_path" env:"GEOIP_DATABASE_PATH"`
+	// Заголовок с кодом страны от прокси перед сервисом, например CF-IPCountry.
+	// Задавайте только если прокси перезаписывает его у каждого запроса
+	CountryHeader string `yaml:"country_header"`
+}
+
+// LinkPassword - настройки ссылок, защищенных паролем.
+type LinkPassword struct {
+	// Ключ подписи куки доступа. Должен совпадать на всех инстансах;
+	// если не задан, генерируется при старте
+	CookieSecret string        `yaml:"cookie_secret" env:"LINK_COOKIE_SECRET"`
+	CookieTTL    time.Duration `yaml:"cookie_ttl" env-default:"1h"`
+
+	// Не больше MaxAttempts попыток ввода пароля с одного адреса за AttemptWindow
+	MaxAttempts   int           `yaml:"max_attempts" env-default:"5"`
+	AttemptWindow time.Duration `yaml:"attempt_window" env-default:"15m"`
+
+	// Не больше AliasMaxAttempts попыток к одной ссылке со всех адресов,
+	// после чего ввод пароля блокируется на AliasLockout. Защищает от
+	// перебора с многих адресов, которого не видит лимит по адресу.
+	// 0 - без общего лимита
+	AliasMaxAttempts int           `yaml:"alias_max_attempts" env-default:"100"`
+	AliasLockout     time.Duration `yaml:"alias_lockout" env-default:"15m"`
 }
 
 func MustLoad() *Config {
@@ -48,5 +378,11 @@ func MustLoad() *Config {
         log.Fatalf("error reading config file: %s", err)
     }
 
+    switch cfg.Redirect.DefaultStatus {
+    case 301, 302, 303, 307, 308:
+    default:
+        log.Fatalf("invalid redirect.default_status: %d", cfg.Redirect.DefaultStatus)
+    }
+
     return &cfg
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Tbits007/url-shortener/internal/lib/basicauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// BasicAuth проверяет учетные данные из метаданных authorization
+// ("Basic base64(user:pass)") так же, как middleware auth в HTTP API.
+// Методы, полное имя которых начинается с одного из public, доступны
+// без авторизации: так балансировщик опрашивает сервис здоровья.
+func BasicAuth(creds map[string]string, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !authorized(ctx, creds, public, info.FullMethod) {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// BasicAuthStream - то же для потоковых вызовов.
+func BasicAuthStream(creds map[string]string, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !authorized(ss.Context(), creds, public, info.FullMethod) {
+			return status.Error(codes.Unauthenticated, "unauthorized")
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func authorized(ctx context.Context, creds map[string]string, public []string, method string) bool {
+	for _, prefix := range public {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		user, pass, ok := basicauth.Parse(header)
+		if ok && basicauth.Check(creds, user, pass) {
+			return true
+		}
+	}
+
+	return false
+}
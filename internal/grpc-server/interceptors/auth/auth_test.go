@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestBasicAuth(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		user         string
+		password     string
+		noAuth       bool
+		expectedCode codes.Code
+	}{
+		{
+			name:         "valid credentials",
+			method:       "/shortener.v1.LinkService/GetLink",
+			user:         "admin",
+			password:     "secret",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "wrong password",
+			method:       "/shortener.v1.LinkService/GetLink",
+			user:         "admin",
+			password:     "wrong",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "unknown user",
+			method:       "/shortener.v1.LinkService/GetLink",
+			user:         "guest",
+			password:     "secret",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "no credentials",
+			method:       "/shortener.v1.LinkService/GetLink",
+			noAuth:       true,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "public method",
+			method:       "/grpc.health.v1.Health/Check",
+			noAuth:       true,
+			expectedCode: codes.OK,
+		},
+	}
+
+	interceptor := BasicAuth(map[string]string{"admin": "secret"}, "/grpc.health.v1.Health/")
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if !tc.noAuth {
+				creds := base64.StdEncoding.EncodeToString([]byte(tc.user + ":" + tc.password))
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Basic "+creds))
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestBasicAuthStream(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		noAuth       bool
+		expectedCode codes.Code
+	}{
+		{
+			name:         "valid credentials",
+			method:       "/shortener.v1.LinkService/Watch",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "no credentials",
+			method:       "/shortener.v1.LinkService/Watch",
+			noAuth:       true,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "public method",
+			method:       "/grpc.health.v1.Health/Watch",
+			noAuth:       true,
+			expectedCode: codes.OK,
+		},
+	}
+
+	interceptor := BasicAuthStream(map[string]string{"admin": "secret"}, "/grpc.health.v1.Health/")
+	handler := func(srv any, ss grpc.ServerStream) error {
+		return nil
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if !tc.noAuth {
+				creds := base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Basic "+creds))
+			}
+
+			err := interceptor(nil, &testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tc.method}, handler)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
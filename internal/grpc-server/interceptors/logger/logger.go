@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// New логирует каждый вызов, как middleware logger логирует HTTP-запросы.
+// Идентификатор запроса берется из контекста: перед New должен стоять
+// перехватчик requestid.
+func New(log *slog.Logger) grpc.UnaryServerInterceptor {
+	log = log.With(
+		slog.String("component", "interceptor/logger"),
+	)
+
+	log.Info("logger interceptor enabled")
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		entry := callLogger(ctx, log, info.FullMethod)
+
+		t1 := time.Now()
+
+		res, err := handler(ctx, req)
+
+		entry.Info("request completed",
+			slog.String("code", status.Code(err).String()),
+			slog.String("duration", time.Since(t1).String()),
+		)
+
+		return res, err
+	}
+}
+
+// NewStream логирует потоковые вызовы: сервис здоровья (Watch)
+// и reflection. В LinkService потоковых методов нет.
+func NewStream(log *slog.Logger) grpc.StreamServerInterceptor {
+	log = log.With(
+		slog.String("component", "interceptor/logger"),
+	)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		entry := callLogger(ss.Context(), log, info.FullMethod)
+
+		t1 := time.Now()
+
+		err := handler(srv, ss)
+
+		entry.Info("stream completed",
+			slog.String("code", status.Code(err).String()),
+			slog.String("duration", time.Since(t1).String()),
+		)
+
+		return err
+	}
+}
+
+func callLogger(ctx context.Context, log *slog.Logger, method string) *slog.Logger {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	return log.With(
+		slog.String("method", method),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("user_agent", first(md.Get("user-agent"))),
+		slog.String("request_id", middleware.GetReqID(ctx)),
+	).With(tracing.LogAttrs(ctx)...)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,62 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/Tbits007/url-shortener/internal/lib/random"
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Key - метаданные с идентификатором запроса, аналог заголовка X-Request-Id.
+const Key = "x-request-id"
+
+const idLength = 16
+
+// Unary берет идентификатор запроса из метаданных клиента или создает
+// новый и кладет его в контекст под ключом chi, как middleware.RequestID
+// в HTTP: middleware.GetReqID работает и в обработчиках gRPC. Клиент
+// получает идентификатор в заголовке ответа. Должен стоять первым в цепочке.
+func Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, id := withRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(Key, id))
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - то же для потоковых вызовов.
+func Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(Key, id))
+
+		return handler(srv, &stream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withRequestID(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var id string
+	if values := md.Get(Key); len(values) > 0 {
+		id = values[0]
+	}
+	if id == "" {
+		id = random.NewRandomString(idLength)
+	}
+
+	return context.WithValue(ctx, middleware.RequestIDKey, id), id
+}
+
+// stream подменяет контекст потока.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
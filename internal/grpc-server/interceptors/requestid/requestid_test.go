@@ -0,0 +1,82 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnary(t *testing.T) {
+	cases := []struct {
+		name       string
+		incoming   metadata.MD
+		expectedID string
+	}{
+		{
+			name:       "propagates client id",
+			incoming:   metadata.Pairs(Key, "req-42"),
+			expectedID: "req-42",
+		},
+		{
+			name: "generates id",
+		},
+		{
+			name:     "empty id is replaced",
+			incoming: metadata.Pairs(Key, ""),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tc.incoming)
+
+			var got string
+			_, err := Unary()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+				got = middleware.GetReqID(ctx)
+				return nil, nil
+			})
+			require.NoError(t, err)
+
+			if tc.expectedID != "" {
+				assert.Equal(t, tc.expectedID, got)
+			} else {
+				assert.Len(t, got, idLength)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Key, "req-42"))
+	ss := &testStream{ctx: ctx}
+
+	var got string
+	err := Stream()(nil, ss, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+		got = middleware.GetReqID(ss.Context())
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "req-42", got)
+	assert.Equal(t, []string{"req-42"}, ss.header.Get(Key))
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
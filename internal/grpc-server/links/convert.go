@@ -0,0 +1,164 @@
+package links
+
+import (
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	shortenerv1 "github.com/Tbits007/url-shortener/pkg/api/shortener/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toRequest переводит ссылку из gRPC в запрос на сохранение, чтобы
+// проверять ее теми же правилами, что и JSON HTTP API.
+func toRequest(l *shortenerv1.Link) save.Request {
+	req := save.Request{
+		URL:           l.GetUrl(),
+		Alias:         l.GetAlias(),
+		Title:         l.GetTitle(),
+		RedirectType:  int(l.GetRedirectType()),
+		ForwardQuery:  l.GetForwardQuery(),
+		QueryConflict: l.GetQueryConflict(),
+		ForwardPath:   l.GetForwardPath(),
+		UTM: storage.UTM{
+			Source:   l.GetUtm().GetSource(),
+			Medium:   l.GetUtm().GetMedium(),
+			Campaign: l.GetUtm().GetCampaign(),
+			Term:     l.GetUtm().GetTerm(),
+			Content:  l.GetUtm().GetContent(),
+		},
+		UTMTemplate:  l.GetUtmTemplate(),
+		Password:     l.GetPassword(),
+		MaxClicks:    int(l.GetMaxClicks()),
+		ActiveFrom:   toTime(l.GetActiveFrom()),
+		ActiveUntil:  toTime(l.GetActiveUntil()),
+		PendingURL:   l.GetPendingUrl(),
+		ExpiredURL:   l.GetExpiredUrl(),
+		Interstitial: l.GetInterstitial(),
+		OG: storage.OpenGraph{
+			Title:       l.GetOg().GetTitle(),
+			Description: l.GetOg().GetDescription(),
+			Image:       l.GetOg().GetImage(),
+		},
+	}
+
+	for _, rule := range l.GetTargeting() {
+		req.Targeting = append(req.Targeting, storage.TargetRule{
+			Priority: int(rule.GetPriority()),
+			OS:       rule.GetOs(),
+			Device:   rule.GetDevice(),
+			Bot:      rule.Bot,
+			Country:  rule.GetCountry(),
+			Language: rule.GetLanguage(),
+			URL:      rule.GetUrl(),
+		})
+	}
+	for _, v := range l.GetVariants() {
+		req.Variants = append(req.Variants, storage.Variant{
+			Name:   v.GetName(),
+			URL:    v.GetUrl(),
+			Weight: int(v.GetWeight()),
+		})
+	}
+
+	return req
+}
+
+// toProto описывает ссылку так же, как info.NewResponse: без хэша
+// пароля и без нулевых значений.
+func toProto(link storage.Link) *shortenerv1.Link {
+	res := &shortenerv1.Link{
+		Alias:             link.Alias,
+		Url:               link.URL,
+		Title:             link.Title,
+		RedirectType:      int32(link.RedirectType),
+		ForwardQuery:      link.ForwardQuery,
+		QueryConflict:     link.QueryConflict,
+		ForwardPath:       link.ForwardPath,
+		UtmTemplate:       link.UTMTemplate,
+		MaxClicks:         int32(link.MaxClicks),
+		ActiveFrom:        toTimestamp(link.ActiveFrom),
+		ActiveUntil:       toTimestamp(link.ActiveUntil),
+		PendingUrl:        link.PendingURL,
+		ExpiredUrl:        link.ExpiredURL,
+		Interstitial:      link.Interstitial,
+		Owner:             link.Owner,
+		CreatedAt:         toTimestamp(link.CreatedAt),
+		PasswordProtected: link.PasswordHash != "",
+	}
+	if link.MaxClicks > 0 {
+		res.ClicksRemaining = int32(link.ClicksRemaining)
+	}
+	if link.UTM != (storage.UTM{}) {
+		res.Utm = &shortenerv1.UTM{
+			Source:   link.UTM.Source,
+			Medium:   link.UTM.Medium,
+			Campaign: link.UTM.Campaign,
+			Term:     link.UTM.Term,
+			Content:  link.UTM.Content,
+		}
+	}
+	if link.OG != (storage.OpenGraph{}) {
+		res.Og = &shortenerv1.OpenGraph{
+			Title:       link.OG.Title,
+			Description: link.OG.Description,
+			Image:       link.OG.Image,
+		}
+	}
+
+	for _, rule := range link.Targeting {
+		res.Targeting = append(res.Targeting, &shortenerv1.TargetRule{
+			Priority: int32(rule.Priority),
+			Os:       rule.OS,
+			Device:   rule.Device,
+			Bot:      rule.Bot,
+			Country:  rule.Country,
+			Language: rule.Language,
+			Url:      rule.URL,
+		})
+	}
+	for _, v := range link.Variants {
+		res.Variants = append(res.Variants, &shortenerv1.Variant{
+			Name:   v.Name,
+			Url:    v.URL,
+			Weight: int32(v.Weight),
+		})
+	}
+
+	return res
+}
+
+func toStats(link storage.Link, clicks storage.ClickStats) *shortenerv1.LinkStats {
+	res := &shortenerv1.LinkStats{
+		Alias:  link.Alias,
+		Clicks: int64(clicks.Total),
+	}
+
+	for _, v := range link.Variants {
+		res.Variants = append(res.Variants, &shortenerv1.VariantStats{
+			Name:   v.Name,
+			Url:    v.URL,
+			Weight: int32(v.Weight),
+			Clicks: int64(clicks.Variants[v.Name]),
+		})
+	}
+
+	return res
+}
+
+func toTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+	return &t
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -0,0 +1,72 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Домен ErrorInfo. Reason в нем - тот же машиночитаемый код, что поле
+// code в ответах HTTP API.
+const errorDomain = "url-shortener"
+
+func newError(c codes.Code, reason, msg string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(c, msg)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}}
+	if len(violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
+
+// invalidArgument - ошибки проверки запроса, по одному нарушению на поле.
+func invalidArgument(details ...resp.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(details))
+	for _, d := range details {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       d.Field,
+			Description: d.Message,
+		})
+	}
+
+	return newError(codes.InvalidArgument, resp.CodeValidationFailed, resp.ValidationFailed(details...).Error, violations...)
+}
+
+func utmTemplateNotFound() error {
+	return newError(codes.InvalidArgument, resp.CodeUTMTemplateNotFound, "utm template not found",
+		&errdetails.BadRequest_FieldViolation{Field: "utm_template", Description: "utm template not found"},
+	)
+}
+
+// storageError переводит ошибки хранилища в статусы gRPC так же,
+// как обработчики HTTP переводят их в коды ответа.
+func storageError(log *slog.Logger, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound):
+		log.Info("url not found", sl.Err(err))
+		return newError(codes.NotFound, resp.CodeNotFound, "not found")
+	case errors.Is(err, context.Canceled):
+		log.Info("request canceled by client", sl.Err(err))
+		return newError(codes.Canceled, resp.CodeRequestCanceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Error("storage timeout", sl.Err(err))
+		return newError(codes.Unavailable, resp.CodeStorageTimeout, "storage timeout")
+	default:
+		log.Error("storage error", sl.Err(err))
+		return newError(codes.Internal, resp.CodeInternal, "internal error")
+	}
+}
@@ -0,0 +1,361 @@
+package links
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/basicauth"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	shortenerv1 "github.com/Tbits007/url-shortener/pkg/api/shortener/v1"
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// Поля Link, которые заполняет сервер. В маске обновления их быть не может.
+var outputOnly = map[protoreflect.Name]struct{}{
+	"owner":              {},
+	"created_at":         {},
+	"password_protected": {},
+	"clicks_remaining":   {},
+}
+
+type Storage interface {
+	SaveURL(ctx context.Context, link storage.Link) error
+	GetURL(ctx context.Context, alias string) (storage.Link, error)
+	UpdateURL(ctx context.Context, link storage.Link) error
+	DeleteURL(ctx context.Context, alias string) error
+	ListURLs(ctx context.Context, filter storage.ListFilter) ([]storage.Link, error)
+	GetClickStats(ctx context.Context, alias string) (storage.ClickStats, error)
+}
+
+// Service - LinkService поверх того же хранилища и тех же правил
+// проверки, что и обработчики /api/v1/links.
+type Service struct {
+	shortenerv1.UnimplementedLinkServiceServer
+
+	log     *slog.Logger
+	storage Storage
+}
+
+func New(log *slog.Logger, storage Storage) *Service {
+	return &Service{log: log, storage: storage}
+}
+
+func (s *Service) CreateLink(ctx context.Context, in *shortenerv1.CreateLinkRequest) (*shortenerv1.Link, error) {
+	const op = "grpc.links.CreateLink"
+
+	log := s.logger(ctx, op)
+
+	req := toRequest(in.GetLink())
+	log.Info("request decoded", slog.Any("req", req))
+
+	if details := req.Validate(); len(details) > 0 {
+		log.Info("invalid request", slog.Any("details", details))
+		return nil, invalidArgument(details...)
+	}
+
+	if save.IsReserved(req.Alias) {
+		log.Info("alias is reserved", slog.String("alias", req.Alias))
+		return nil, newError(codes.AlreadyExists, resp.CodeAliasReserved, "alias is reserved")
+	}
+
+	// Владелец - пользователь basic auth, как и в HTTP API
+	link, err := req.NewLink(user(ctx))
+	if err != nil {
+		log.Error("failed to hash password", sl.Err(err))
+		return nil, newError(codes.Internal, resp.CodeInternal, "failed to add url")
+	}
+
+	err = s.storage.SaveURL(ctx, link)
+	if errors.Is(err, storage.ErrURLExists) {
+		log.Info("url already exists", slog.String("alias", link.Alias))
+		return nil, newError(codes.AlreadyExists, resp.CodeAliasExists, "url already exists")
+	}
+	if errors.Is(err, storage.ErrUTMTemplateNotFound) {
+		log.Info("utm template not found", slog.String("template", link.UTMTemplate))
+		return nil, utmTemplateNotFound()
+	}
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	metrics.LinksCreatedTotal.Inc()
+
+	// Дату создания и остаток кликов проставляет база
+	return s.get(storage.WithPrimary(ctx), log, link.Alias)
+}
+
+func (s *Service) GetLink(ctx context.Context, in *shortenerv1.GetLinkRequest) (*shortenerv1.Link, error) {
+	const op = "grpc.links.GetLink"
+
+	// Как и /api/v1/links/{alias}, читаем из primary: ссылку часто
+	// запрашивают сразу после создания
+	return s.get(storage.WithPrimary(ctx), s.logger(ctx, op), in.GetAlias())
+}
+
+func (s *Service) UpdateLink(ctx context.Context, in *shortenerv1.UpdateLinkRequest) (*shortenerv1.Link, error) {
+	const op = "grpc.links.UpdateLink"
+
+	log := s.logger(ctx, op)
+
+	alias := in.GetLink().GetAlias()
+	if alias == "" {
+		return nil, newError(codes.NotFound, resp.CodeNotFound, "not found")
+	}
+
+	paths, details := maskPaths(in)
+	if len(details) > 0 {
+		log.Info("invalid update mask", slog.Any("details", details))
+		return nil, invalidArgument(details...)
+	}
+
+	ctx = storage.WithPrimary(ctx)
+
+	current, err := s.storage.GetURL(ctx, alias)
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	// Поля из маски заменяются целиком, остальные остаются как были
+	merged := toProto(current).ProtoReflect()
+	src := in.GetLink().ProtoReflect()
+	for _, fd := range paths {
+		if src.Has(fd) {
+			merged.Set(fd, src.Get(fd))
+		} else {
+			merged.Clear(fd)
+		}
+	}
+
+	req := toRequest(merged.Interface().(*shortenerv1.Link))
+	// Алиас не меняется, а ссылки, созданные до ограничения набора
+	// символов в алиасе, тоже должны оставаться изменяемыми
+	req.Alias = ""
+	if details := req.Validate(); len(details) > 0 {
+		log.Info("invalid request", slog.Any("details", details))
+		return nil, invalidArgument(details...)
+	}
+
+	updated := req.Link(alias)
+	updated.Owner = current.Owner
+	updated.CreatedAt = current.CreatedAt
+	updated.PasswordHash = current.PasswordHash
+	updated.Version = current.Version
+	if _, ok := paths["password"]; ok {
+		updated.PasswordHash = ""
+		if req.Password != "" {
+			updated.PasswordHash, err = linkpassword.Hash(req.Password)
+			if err != nil {
+				log.Error("failed to hash password", sl.Err(err))
+				return nil, newError(codes.Internal, resp.CodeInternal, "failed to update url")
+			}
+		}
+	}
+
+	err = s.storage.UpdateURL(ctx, updated)
+	if errors.Is(err, storage.ErrVersionConflict) {
+		// Ссылку изменили между чтением и записью. Aborted клиенты gRPC
+		// повторяют целиком, вместе с чтением
+		log.Info("url changed concurrently", slog.String("alias", alias))
+		return nil, newError(codes.Aborted, resp.CodeUpdateConflict, "url was updated concurrently, retry")
+	}
+	if errors.Is(err, storage.ErrUTMTemplateNotFound) {
+		log.Info("utm template not found", slog.String("template", updated.UTMTemplate))
+		return nil, utmTemplateNotFound()
+	}
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	log.Info("url updated", slog.String("alias", alias))
+
+	// Остаток кликов пересчитывает база
+	return s.get(ctx, log, alias)
+}
+
+func (s *Service) DeleteLink(ctx context.Context, in *shortenerv1.DeleteLinkRequest) (*emptypb.Empty, error) {
+	const op = "grpc.links.DeleteLink"
+
+	log := s.logger(ctx, op)
+
+	if in.GetAlias() == "" {
+		return nil, newError(codes.NotFound, resp.CodeNotFound, "not found")
+	}
+
+	if err := s.storage.DeleteURL(ctx, in.GetAlias()); err != nil {
+		return nil, storageError(log, err)
+	}
+
+	log.Info("url deleted", slog.String("alias", in.GetAlias()))
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Service) ListLinks(ctx context.Context, in *shortenerv1.ListLinksRequest) (*shortenerv1.ListLinksResponse, error) {
+	const op = "grpc.links.ListLinks"
+
+	log := s.logger(ctx, op)
+
+	size := int(in.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, invalidArgument(resp.FieldError{
+			Field:   "page_size",
+			Rule:    "min",
+			Param:   "0",
+			Message: "page_size must be 0 or greater",
+		})
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(in.GetPageToken())
+	if err != nil {
+		log.Info("invalid page token", sl.Err(err))
+		return nil, invalidArgument(resp.FieldError{
+			Field:   "page_token",
+			Rule:    "page_token",
+			Message: "page_token is invalid",
+		})
+	}
+
+	// Лишняя ссылка показывает, есть ли следующая страница
+	links, err := s.storage.ListURLs(ctx, storage.ListFilter{
+		Owner: in.GetOwner(),
+		After: string(after),
+		Limit: size + 1,
+	})
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	res := &shortenerv1.ListLinksResponse{}
+	if len(links) > size {
+		links = links[:size]
+		res.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(links[size-1].Alias))
+	}
+	for _, link := range links {
+		res.Links = append(res.Links, toProto(link))
+	}
+
+	return res, nil
+}
+
+func (s *Service) GetLinkStats(ctx context.Context, in *shortenerv1.GetLinkStatsRequest) (*shortenerv1.LinkStats, error) {
+	const op = "grpc.links.GetLinkStats"
+
+	log := s.logger(ctx, op)
+
+	if in.GetAlias() == "" {
+		return nil, newError(codes.NotFound, resp.CodeNotFound, "not found")
+	}
+
+	// Ссылка нужна, чтобы показать и варианты, по которым еще не было переходов
+	link, err := s.storage.GetURL(ctx, in.GetAlias())
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	clicks, err := s.storage.GetClickStats(ctx, in.GetAlias())
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	return toStats(link, clicks), nil
+}
+
+func (s *Service) get(ctx context.Context, log *slog.Logger, alias string) (*shortenerv1.Link, error) {
+	if alias == "" {
+		return nil, newError(codes.NotFound, resp.CodeNotFound, "not found")
+	}
+
+	link, err := s.storage.GetURL(ctx, alias)
+	if err != nil {
+		return nil, storageError(log, err)
+	}
+
+	return toProto(link), nil
+}
+
+func (s *Service) logger(ctx context.Context, op string) *slog.Logger {
+	return s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(ctx)),
+	).With(tracing.LogAttrs(ctx)...)
+}
+
+// maskPaths возвращает поля Link, которые меняет запрос. Без маски это
+// все заданные в запросе поля, кроме алиаса и выходных.
+func maskPaths(in *shortenerv1.UpdateLinkRequest) (map[protoreflect.Name]protoreflect.FieldDescriptor, []resp.FieldError) {
+	fields := (&shortenerv1.Link{}).ProtoReflect().Descriptor().Fields()
+	paths := make(map[protoreflect.Name]protoreflect.FieldDescriptor)
+
+	if len(in.GetUpdateMask().GetPaths()) == 0 {
+		in.GetLink().ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if _, ok := outputOnly[fd.Name()]; !ok && fd.Name() != "alias" {
+				paths[fd.Name()] = fd
+			}
+			return true
+		})
+
+		return paths, nil
+	}
+
+	var details []resp.FieldError
+	for _, path := range in.GetUpdateMask().GetPaths() {
+		name := protoreflect.Name(path)
+		fd := fields.ByName(name)
+
+		_, readonly := outputOnly[name]
+		switch {
+		case fd == nil:
+			details = append(details, resp.FieldError{
+				Field:   "update_mask",
+				Rule:    "field",
+				Param:   path,
+				Message: "update_mask has unknown field " + path,
+			})
+		case readonly || name == "alias":
+			details = append(details, resp.FieldError{
+				Field:   path,
+				Rule:    "readonly",
+				Message: path + " cannot be changed",
+			})
+		default:
+			paths[name] = fd
+		}
+	}
+
+	return paths, details
+}
+
+// user - пользователь basic auth, под которым пришел запрос. Проверку
+// пароля к этому моменту уже сделал перехватчик auth.
+func user(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		if name, _, ok := basicauth.Parse(header); ok {
+			return name
+		}
+	}
+
+	return ""
+}
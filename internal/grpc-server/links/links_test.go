@@ -0,0 +1,334 @@
+package links
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	shortenerv1 "github.com/Tbits007/url-shortener/pkg/api/shortener/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestCreateLink(t *testing.T) {
+	cases := []struct {
+		name           string
+		link           *shortenerv1.Link
+		saveError      error
+		expectedCode   codes.Code
+		expectedReason string
+	}{
+		{
+			name:         "success",
+			link:         &shortenerv1.Link{Url: "https://example.com", Alias: "docs", Password: "s3cret"},
+			expectedCode: codes.OK,
+		},
+		{
+			name:           "invalid url",
+			link:           &shortenerv1.Link{Url: "not a url"},
+			expectedCode:   codes.InvalidArgument,
+			expectedReason: "validation_failed",
+		},
+		{
+			name:           "reserved alias",
+			link:           &shortenerv1.Link{Url: "https://example.com", Alias: "healthz"},
+			expectedCode:   codes.AlreadyExists,
+			expectedReason: "alias_reserved",
+		},
+		{
+			name:           "alias exists",
+			link:           &shortenerv1.Link{Url: "https://example.com", Alias: "docs"},
+			saveError:      storage.ErrURLExists,
+			expectedCode:   codes.AlreadyExists,
+			expectedReason: "alias_exists",
+		},
+		{
+			name:           "unknown utm template",
+			link:           &shortenerv1.Link{Url: "https://example.com", Alias: "docs", UtmTemplate: "missing"},
+			saveError:      storage.ErrUTMTemplateNotFound,
+			expectedCode:   codes.InvalidArgument,
+			expectedReason: "utm_template_not_found",
+		},
+		{
+			name:           "storage timeout",
+			link:           &shortenerv1.Link{Url: "https://example.com", Alias: "docs"},
+			saveError:      fmt.Errorf("storage: %w", context.DeadlineExceeded),
+			expectedCode:   codes.Unavailable,
+			expectedReason: "storage_timeout",
+		},
+		{
+			name:           "storage error",
+			link:           &shortenerv1.Link{Url: "https://example.com", Alias: "docs"},
+			saveError:      errors.New("database error"),
+			expectedCode:   codes.Internal,
+			expectedReason: "internal_error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := NewMockStorage(t)
+
+			var saved storage.Link
+			mockStorage.On("SaveURL", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { saved = args.Get(1).(storage.Link) }).
+				Return(tc.saveError).Maybe()
+			mockStorage.On("GetURL", mock.Anything, "docs").
+				Return(func(context.Context, string) storage.Link { return saved }, nil).Maybe()
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:secret")),
+			))
+
+			res, err := New(slogdiscard.NewDiscardLogger(), mockStorage).
+				CreateLink(ctx, &shortenerv1.CreateLinkRequest{Link: tc.link})
+
+			require.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode != codes.OK {
+				assert.Equal(t, tc.expectedReason, reason(t, err))
+				return
+			}
+
+			assert.Equal(t, "docs", res.GetAlias())
+			assert.Equal(t, "admin", res.GetOwner())
+			assert.True(t, res.GetPasswordProtected())
+			assert.Empty(t, res.GetPassword())
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(saved.PasswordHash), []byte("s3cret")))
+		})
+	}
+}
+
+func TestCreateLink_FieldViolations(t *testing.T) {
+	_, err := New(slogdiscard.NewDiscardLogger(), NewMockStorage(t)).
+		CreateLink(context.Background(), &shortenerv1.CreateLinkRequest{Link: &shortenerv1.Link{
+			Url:       "https://example.com",
+			Targeting: []*shortenerv1.TargetRule{{Os: []string{"symbian"}, Url: "https://example.com/m"}},
+		}})
+
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+	require.Len(t, violations, 1)
+	// Поля называются как в JSON HTTP API
+	assert.Equal(t, "targeting[0].os[0]", violations[0].GetField())
+}
+
+func TestUpdateLink(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := storage.Link{
+		Alias:        "docs",
+		URL:          "https://example.com/docs",
+		Version:      4,
+		Title:        "Docs",
+		Owner:        "admin",
+		UTM:          storage.UTM{Source: "newsletter", Medium: "email"},
+		PasswordHash: "$2a$10$existing",
+		ActiveUntil:  until,
+		Targeting: []storage.TargetRule{
+			{OS: []string{"ios"}, URL: "https://apps.apple.com/app/id1"},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		link         *shortenerv1.Link
+		paths        []string
+		getError     error
+		updateError  error
+		expected     func(link storage.Link) bool
+		expectedCode codes.Code
+	}{
+		{
+			name:  "fields outside the mask are kept",
+			link:  &shortenerv1.Link{Alias: "docs", Title: "Guide", Url: "https://ignored.example.com"},
+			paths: []string{"title"},
+			expected: func(link storage.Link) bool {
+				return link.Title == "Guide" && link.URL == current.URL &&
+					link.UTM == current.UTM && link.PasswordHash == current.PasswordHash &&
+					link.Owner == "admin" && link.ActiveUntil.Equal(until) && len(link.Targeting) == 1
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:  "masked messages are replaced",
+			link:  &shortenerv1.Link{Alias: "docs", Utm: &shortenerv1.UTM{Campaign: "launch"}},
+			paths: []string{"utm"},
+			expected: func(link storage.Link) bool {
+				return link.UTM == storage.UTM{Campaign: "launch"}
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:  "unset masked fields are cleared",
+			link:  &shortenerv1.Link{Alias: "docs"},
+			paths: []string{"active_until", "targeting", "password"},
+			expected: func(link storage.Link) bool {
+				return link.ActiveUntil.IsZero() && link.Targeting == nil && link.PasswordHash == ""
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:  "empty mask updates set fields",
+			link:  &shortenerv1.Link{Alias: "docs", Password: "s3cret", Owner: "ignored"},
+			paths: nil,
+			expected: func(link storage.Link) bool {
+				return link.Title == current.Title && link.Owner == "admin" &&
+					bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte("s3cret")) == nil
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "output only field in mask",
+			link:         &shortenerv1.Link{Alias: "docs", Owner: "guest"},
+			paths:        []string{"owner"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "unknown field in mask",
+			link:         &shortenerv1.Link{Alias: "docs"},
+			paths:        []string{"utm.source"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "invalid url",
+			link:         &shortenerv1.Link{Alias: "docs", Url: "not a url"},
+			paths:        []string{"url"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "url not found",
+			link:         &shortenerv1.Link{Alias: "docs", Title: "Guide"},
+			paths:        []string{"title"},
+			getError:     storage.ErrURLNotFound,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "unknown utm template",
+			link:         &shortenerv1.Link{Alias: "docs", UtmTemplate: "missing"},
+			paths:        []string{"utm_template"},
+			updateError:  storage.ErrUTMTemplateNotFound,
+			expected:     func(link storage.Link) bool { return link.UTMTemplate == "missing" },
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "concurrent update",
+			link:         &shortenerv1.Link{Alias: "docs", Title: "Guide"},
+			paths:        []string{"title"},
+			updateError:  storage.ErrVersionConflict,
+			expected:     func(link storage.Link) bool { return link.Version == current.Version },
+			expectedCode: codes.Aborted,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := NewMockStorage(t)
+			mockStorage.On("GetURL", mock.Anything, "docs").Return(current, tc.getError).Maybe()
+			if tc.expected != nil {
+				mockStorage.On("UpdateURL", mock.Anything, mock.MatchedBy(tc.expected)).Return(tc.updateError).Once()
+			}
+
+			req := &shortenerv1.UpdateLinkRequest{Link: tc.link}
+			if tc.paths != nil {
+				req.UpdateMask = &fieldmaskpb.FieldMask{Paths: tc.paths}
+			}
+
+			_, err := New(slogdiscard.NewDiscardLogger(), mockStorage).UpdateLink(context.Background(), req)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestListLinks(t *testing.T) {
+	mockStorage := NewMockStorage(t)
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", Limit: 3}).
+		Return([]storage.Link{{Alias: "a"}, {Alias: "b"}, {Alias: "c"}}, nil).Once()
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", After: "b", Limit: 3}).
+		Return([]storage.Link{{Alias: "c"}}, nil).Once()
+
+	svc := New(slogdiscard.NewDiscardLogger(), mockStorage)
+
+	first, err := svc.ListLinks(context.Background(), &shortenerv1.ListLinksRequest{PageSize: 2, Owner: "admin"})
+	require.NoError(t, err)
+	require.Len(t, first.GetLinks(), 2)
+	assert.NotEmpty(t, first.GetNextPageToken())
+
+	second, err := svc.ListLinks(context.Background(), &shortenerv1.ListLinksRequest{
+		PageSize:  2,
+		PageToken: first.GetNextPageToken(),
+		Owner:     "admin",
+	})
+	require.NoError(t, err)
+	require.Len(t, second.GetLinks(), 1)
+	assert.Equal(t, "c", second.GetLinks()[0].GetAlias())
+	assert.Empty(t, second.GetNextPageToken())
+
+	_, err = svc.ListLinks(context.Background(), &shortenerv1.ListLinksRequest{PageToken: "%%%"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetLinkStats(t *testing.T) {
+	mockStorage := NewMockStorage(t)
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{
+		Alias: "docs",
+		Variants: []storage.Variant{
+			{Name: "a", URL: "https://example.com/a", Weight: 1},
+			{Name: "b", URL: "https://example.com/b", Weight: 1},
+		},
+	}, nil).Once()
+	mockStorage.On("GetClickStats", mock.Anything, "docs").
+		Return(storage.ClickStats{Total: 3, Variants: map[string]int{"a": 3}}, nil).Once()
+
+	res, err := New(slogdiscard.NewDiscardLogger(), mockStorage).
+		GetLinkStats(context.Background(), &shortenerv1.GetLinkStatsRequest{Alias: "docs"})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), res.GetClicks())
+	require.Len(t, res.GetVariants(), 2)
+	assert.Equal(t, int64(3), res.GetVariants()[0].GetClicks())
+	assert.Equal(t, int64(0), res.GetVariants()[1].GetClicks())
+}
+
+func TestDeleteLink(t *testing.T) {
+	mockStorage := NewMockStorage(t)
+	mockStorage.On("DeleteURL", mock.Anything, "docs").Return(nil).Once()
+	mockStorage.On("DeleteURL", mock.Anything, "missing").Return(storage.ErrURLNotFound).Once()
+
+	svc := New(slogdiscard.NewDiscardLogger(), mockStorage)
+
+	_, err := svc.DeleteLink(context.Background(), &shortenerv1.DeleteLinkRequest{Alias: "docs"})
+	assert.NoError(t, err)
+
+	_, err = svc.DeleteLink(context.Background(), &shortenerv1.DeleteLinkRequest{Alias: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func reason(t *testing.T, err error) string {
+	t.Helper()
+
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
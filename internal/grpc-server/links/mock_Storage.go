@@ -0,0 +1,351 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package links
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStorage is an autogenerated mock type for the Storage type
+type MockStorage struct {
+	mock.Mock
+}
+
+type MockStorage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorage) EXPECT() *MockStorage_Expecter {
+	return &MockStorage_Expecter{mock: &_m.Mock}
+}
+
+// DeleteURL provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) DeleteURL(ctx context.Context, alias string) error {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_DeleteURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteURL'
+type MockStorage_DeleteURL_Call struct {
+	*mock.Call
+}
+
+// DeleteURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) DeleteURL(ctx interface{}, alias interface{}) *MockStorage_DeleteURL_Call {
+	return &MockStorage_DeleteURL_Call{Call: _e.mock.On("DeleteURL", ctx, alias)}
+}
+
+func (_c *MockStorage_DeleteURL_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_DeleteURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_DeleteURL_Call) Return(_a0 error) *MockStorage_DeleteURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_DeleteURL_Call) RunAndReturn(run func(context.Context, string) error) *MockStorage_DeleteURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetClickStats provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) GetClickStats(ctx context.Context, alias string) (storage.ClickStats, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClickStats")
+	}
+
+	var r0 storage.ClickStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.ClickStats, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.ClickStats); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.ClickStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClickStats'
+type MockStorage_GetClickStats_Call struct {
+	*mock.Call
+}
+
+// GetClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) GetClickStats(ctx interface{}, alias interface{}) *MockStorage_GetClickStats_Call {
+	return &MockStorage_GetClickStats_Call{Call: _e.mock.On("GetClickStats", ctx, alias)}
+}
+
+func (_c *MockStorage_GetClickStats_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_GetClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetClickStats_Call) Return(_a0 storage.ClickStats, _a1 error) *MockStorage_GetClickStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetClickStats_Call) RunAndReturn(run func(context.Context, string) (storage.ClickStats, error)) *MockStorage_GetClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURL")
+	}
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURL'
+type MockStorage_GetURL_Call struct {
+	*mock.Call
+}
+
+// GetURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) GetURL(ctx interface{}, alias interface{}) *MockStorage_GetURL_Call {
+	return &MockStorage_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
+}
+
+func (_c *MockStorage_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockStorage_GetURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockStorage_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListURLs provides a mock function with given fields: ctx, filter
+func (_m *MockStorage) ListURLs(ctx context.Context, filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListURLs")
+	}
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) []storage.Link); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_ListURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListURLs'
+type MockStorage_ListURLs_Call struct {
+	*mock.Call
+}
+
+// ListURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter storage.ListFilter
+func (_e *MockStorage_Expecter) ListURLs(ctx interface{}, filter interface{}) *MockStorage_ListURLs_Call {
+	return &MockStorage_ListURLs_Call{Call: _e.mock.On("ListURLs", ctx, filter)}
+}
+
+func (_c *MockStorage_ListURLs_Call) Run(run func(ctx context.Context, filter storage.ListFilter)) *MockStorage_ListURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.ListFilter))
+	})
+	return _c
+}
+
+func (_c *MockStorage_ListURLs_Call) Return(_a0 []storage.Link, _a1 error) *MockStorage_ListURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_ListURLs_Call) RunAndReturn(run func(context.Context, storage.ListFilter) ([]storage.Link, error)) *MockStorage_ListURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveURL provides a mock function with given fields: ctx, link
+func (_m *MockStorage) SaveURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_SaveURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveURL'
+type MockStorage_SaveURL_Call struct {
+	*mock.Call
+}
+
+// SaveURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockStorage_Expecter) SaveURL(ctx interface{}, link interface{}) *MockStorage_SaveURL_Call {
+	return &MockStorage_SaveURL_Call{Call: _e.mock.On("SaveURL", ctx, link)}
+}
+
+func (_c *MockStorage_SaveURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockStorage_SaveURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Link))
+	})
+	return _c
+}
+
+func (_c *MockStorage_SaveURL_Call) Return(_a0 error) *MockStorage_SaveURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_SaveURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockStorage_SaveURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateURL provides a mock function with given fields: ctx, link
+func (_m *MockStorage) UpdateURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_UpdateURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateURL'
+type MockStorage_UpdateURL_Call struct {
+	*mock.Call
+}
+
+// UpdateURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockStorage_Expecter) UpdateURL(ctx interface{}, link interface{}) *MockStorage_UpdateURL_Call {
+	return &MockStorage_UpdateURL_Call{Call: _e.mock.On("UpdateURL", ctx, link)}
+}
+
+func (_c *MockStorage_UpdateURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockStorage_UpdateURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Link))
+	})
+	return _c
+}
+
+func (_c *MockStorage_UpdateURL_Call) Return(_a0 error) *MockStorage_UpdateURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_UpdateURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockStorage_UpdateURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorage creates a new instance of MockStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorage {
+	mock := &MockStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
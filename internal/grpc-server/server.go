@@ -0,0 +1,166 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/grpc-server/interceptors/auth"
+	"github.com/Tbits007/url-shortener/internal/grpc-server/interceptors/logger"
+	"github.com/Tbits007/url-shortener/internal/grpc-server/interceptors/requestid"
+	"github.com/Tbits007/url-shortener/internal/grpc-server/links"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	shortenerv1 "github.com/Tbits007/url-shortener/pkg/api/shortener/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// Server - gRPC API сервиса: LinkService, стандартный сервис здоровья
+// и reflection для grpcurl и подобных клиентов.
+type Server struct {
+	log    *slog.Logger
+	srv    *grpc.Server
+	health *health.Server
+
+	done     chan struct{}
+	stopOnce sync.Once
+	// Последний результат проверки, чтобы писать в лог только смену статуса.
+	// Меняется только из check, вызовы которого не пересекаются
+	ready bool
+}
+
+// Readiness - проверка готовности для сервиса здоровья, та же, что у /readyz.
+// Без Check сервис всегда SERVING.
+type Readiness struct {
+	Check    func(ctx context.Context) error
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// New собирает сервер. Сервис здоровья доступен без авторизации, как /healthz,
+// reflection - тоже: он описывает только схему API. В LinkService потоковых
+// методов нет, но цепочка для потоков та же, чтобы новый потоковый метод
+// не остался без авторизации и логов.
+func New(log *slog.Logger, creds map[string]string, storage links.Storage, ready Readiness) *Server {
+	public := []string{
+		"/" + healthpb.Health_ServiceDesc.ServiceName + "/",
+		"/" + reflectionpb.ServerReflection_ServiceDesc.ServiceName + "/",
+		"/" + reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName + "/",
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			requestid.Unary(),
+			logger.New(log),
+			auth.BasicAuth(creds, public...),
+		),
+		grpc.ChainStreamInterceptor(
+			requestid.Stream(),
+			logger.NewStream(log),
+			auth.BasicAuthStream(creds, public...),
+		),
+	)
+
+	healthSrv := health.NewServer()
+
+	shortenerv1.RegisterLinkServiceServer(srv, links.New(log, storage))
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	reflection.Register(srv)
+
+	// ready: true, чтобы первая же неудачная проверка попала в лог
+	s := &Server{log: log, srv: srv, health: healthSrv, done: make(chan struct{}), ready: true}
+
+	if ready.Check == nil {
+		s.setStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		// Первая проверка синхронно: до нее клиенты видели бы SERVING
+		// у инстанса, который, может быть, не видит базу
+		s.check(ready)
+		go s.watch(ready)
+	}
+
+	return s
+}
+
+// watch повторяет проверку готовности, пока сервер не начал останавливаться.
+func (s *Server) watch(ready Readiness) {
+	ticker := time.NewTicker(ready.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.check(ready)
+		}
+	}
+}
+
+func (s *Server) check(ready Readiness) {
+	ctx, cancel := context.WithTimeout(context.Background(), ready.Timeout)
+	defer cancel()
+
+	if err := ready.Check(ctx); err != nil {
+		if s.ready {
+			s.log.Warn("grpc service is not ready", sl.Err(err))
+		}
+		s.ready = false
+		s.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+		return
+	}
+
+	if !s.ready {
+		s.log.Info("grpc service is ready")
+	}
+	s.ready = true
+	s.setStatus(healthpb.HealthCheckResponse_SERVING)
+}
+
+// setStatus меняет статус сервера целиком (пустое имя сервиса) и LinkService.
+// После Drain вызовы игнорируются.
+func (s *Server) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus("", status)
+	s.health.SetServingStatus(shortenerv1.LinkService_ServiceDesc.ServiceName, status)
+}
+
+// Serve принимает соединения, пока сервер не остановят.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.srv.Serve(lis)
+}
+
+// Drain переводит все сервисы в NOT_SERVING навсегда, не прерывая
+// вызовов: балансировщик уводит трафик, пока сервер еще работает.
+func (s *Server) Drain() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		s.health.Shutdown()
+	})
+}
+
+// Shutdown переводит все сервисы в NOT_SERVING и дожидается текущих
+// вызовов. Если ctx истек раньше, обрывает их, как http.Server.Shutdown.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Drain()
+
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
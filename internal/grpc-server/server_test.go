@@ -0,0 +1,178 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/grpc-server/links"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	shortenerv1 "github.com/Tbits007/url-shortener/pkg/api/shortener/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	mockStorage := links.NewMockStorage(t)
+	mockStorage.On("GetURL", mock.Anything, "docs").
+		Return(storage.Link{Alias: "docs", URL: "https://example.com/docs"}, nil).Maybe()
+
+	conn := newTestConn(t, mockStorage)
+	client := shortenerv1.NewLinkServiceClient(conn)
+
+	t.Run("requires credentials", func(t *testing.T) {
+		_, err := client.GetLink(context.Background(), &shortenerv1.GetLinkRequest{Alias: "docs"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("serves with credentials", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			"authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:secret")),
+		)
+
+		link, err := client.GetLink(ctx, &shortenerv1.GetLinkRequest{Alias: "docs"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/docs", link.GetUrl())
+	})
+
+	t.Run("returns request id", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			"authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:secret")),
+			"x-request-id", "req-42",
+		)
+
+		var header metadata.MD
+		_, err := client.GetLink(ctx, &shortenerv1.GetLinkRequest{Alias: "docs"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"req-42"}, header.Get("x-request-id"))
+	})
+
+	t.Run("health is public", func(t *testing.T) {
+		res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: shortenerv1.LinkService_ServiceDesc.ServiceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+	})
+
+	t.Run("reflection lists the service", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		res, err := stream.Recv()
+		require.NoError(t, err)
+
+		var names []string
+		for _, s := range res.GetListServicesResponse().GetService() {
+			names = append(names, s.GetName())
+		}
+		assert.Contains(t, names, shortenerv1.LinkService_ServiceDesc.ServiceName)
+	})
+}
+
+// Потоковых методов в LinkService нет. Цепочка для потоков все равно
+// собирается в New; если метод появится, этот тест напомнит проверить,
+// что авторизация и логирование покрывают его так же, как унарные вызовы.
+func TestServer_NoStreamingLinkMethods(t *testing.T) {
+	assert.Empty(t, shortenerv1.LinkService_ServiceDesc.Streams)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	srv := New(slogdiscard.NewDiscardLogger(), map[string]string{"admin": "secret"}, links.NewMockStorage(t), Readiness{})
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	res, err := srv.health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+}
+
+func TestServer_Readiness(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	srv := New(slogdiscard.NewDiscardLogger(), map[string]string{"admin": "secret"}, links.NewMockStorage(t), Readiness{
+		Check: func(ctx context.Context) error {
+			if failing.Load() {
+				return errors.New("storage: connection refused")
+			}
+			return nil
+		},
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Second,
+	})
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := srv.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return res.GetStatus()
+	}
+	services := []string{"", shortenerv1.LinkService_ServiceDesc.ServiceName}
+
+	// Первая проверка выполняется еще в New
+	for _, service := range services {
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(service), service)
+	}
+
+	failing.Store(false)
+	for _, service := range services {
+		assert.Eventually(t, func() bool {
+			return status(service) == healthpb.HealthCheckResponse_SERVING
+		}, time.Second, 5*time.Millisecond, service)
+	}
+
+	failing.Store(true)
+	assert.Eventually(t, func() bool {
+		return status("") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	// После Drain успешная проверка статус уже не возвращает
+	failing.Store(false)
+	srv.Drain()
+	time.Sleep(50 * time.Millisecond)
+	for _, service := range services {
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(service), service)
+	}
+}
+
+func newTestConn(t *testing.T, storage links.Storage) *grpc.ClientConn {
+	t.Helper()
+
+	srv := New(slogdiscard.NewDiscardLogger(), map[string]string{"admin": "secret"}, storage, Readiness{})
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	checkStorage    = "storage"
+	checkMigrations = "migrations"
+	checkShutdown   = "shutdown"
+)
+
+type Check struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Checks map[string]Check `json:"checks,omitempty"`
+}
+
+type Storage interface {
+	Ping(ctx context.Context) error
+	CheckMigrations(ctx context.Context) error
+}
+
+// NewLiveness отвечает 200, пока процесс жив и обрабатывает запросы.
+// Зависимости намеренно не проверяются: иначе оркестратор будет
+// перезапускать сервис при каждой недоступности базы.
+func NewLiveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, Response{Response: resp.OK()})
+	}
+}
+
+// NewReadiness проверяет, что сервис готов принимать трафик:
+// база доступна, миграции применены и сервер не останавливается.
+func NewReadiness(log *slog.Logger, storage Storage, shuttingDown *atomic.Bool, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.NewReadiness"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		checks := readiness(ctx, storage, shuttingDown)
+
+		res := Response{Response: resp.OK(), Checks: checks}
+		for name, c := range checks {
+			if c.Status != resp.StatusOK {
+				log.Warn("readiness check failed", slog.String("check", name), slog.String("error", c.Error))
+				res.Response = resp.Error(resp.CodeNotReady, "not ready")
+			}
+		}
+
+		if res.Status != resp.StatusOK {
+			render.Status(r, http.StatusServiceUnavailable)
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+// Ready выполняет те же проверки, что /readyz, и возвращает ошибку
+// первой не прошедшей. Нужна тем, кто сообщает о готовности не по HTTP,
+// например сервису здоровья gRPC.
+func Ready(ctx context.Context, storage Storage, shuttingDown *atomic.Bool) error {
+	checks := readiness(ctx, storage, shuttingDown)
+
+	for _, name := range slices.Sorted(maps.Keys(checks)) {
+		if c := checks[name]; c.Status != resp.StatusOK {
+			return fmt.Errorf("%s: %s", name, c.Error)
+		}
+	}
+
+	return nil
+}
+
+func readiness(ctx context.Context, storage Storage, shuttingDown *atomic.Bool) map[string]Check {
+	checks := map[string]Check{
+		checkStorage:    check(storage.Ping(ctx)),
+		checkMigrations: check(storage.CheckMigrations(ctx)),
+		checkShutdown:   {Status: resp.StatusOK},
+	}
+	if shuttingDown.Load() {
+		checks[checkShutdown] = Check{Status: resp.StatusError, Error: "server is shutting down"}
+	}
+
+	return checks
+}
+
+func check(err error) Check {
+	if err != nil {
+		return Check{Status: resp.StatusError, Error: err.Error()}
+	}
+
+	return Check{Status: resp.StatusOK}
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	NewLiveness()(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, w.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		pingError      error
+		migrationError error
+		shuttingDown   bool
+		expectedCode   int
+		failedCheck    string
+	}{
+		{
+			name:         "ready",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "storage unreachable",
+			pingError:    errors.New("connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			failedCheck:  checkStorage,
+		},
+		{
+			name:           "migrations pending",
+			migrationError: storage.ErrMigrationsPending,
+			expectedCode:   http.StatusServiceUnavailable,
+			failedCheck:    checkMigrations,
+		},
+		{
+			name:         "shutting down",
+			shuttingDown: true,
+			expectedCode: http.StatusServiceUnavailable,
+			failedCheck:  checkShutdown,
+		},
+	}
+
+	mockLog := slogdiscard.NewDiscardLogger()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := NewMockStorage(t)
+			// Второй раз - для Ready: сервис здоровья gRPC проверяет то же, что /readyz
+			mockStorage.On("Ping", mock.Anything).Return(tc.pingError).Twice()
+			mockStorage.On("CheckMigrations", mock.Anything).Return(tc.migrationError).Twice()
+
+			var shuttingDown atomic.Bool
+			shuttingDown.Store(tc.shuttingDown)
+
+			handler := NewReadiness(mockLog, mockStorage, &shuttingDown, time.Second)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			var res Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Len(t, res.Checks, 3)
+
+			for name, c := range res.Checks {
+				if name == tc.failedCheck {
+					assert.Equal(t, "Error", c.Status, name)
+					assert.NotEmpty(t, c.Error, name)
+				} else {
+					assert.Equal(t, "OK", c.Status, name)
+				}
+			}
+
+			err := Ready(context.Background(), mockStorage, &shuttingDown)
+			if tc.failedCheck == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.failedCheck)
+			}
+		})
+	}
+}
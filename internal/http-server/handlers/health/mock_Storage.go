@@ -0,0 +1,128 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package health
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStorage is an autogenerated mock type for the Storage type
+type MockStorage struct {
+	mock.Mock
+}
+
+type MockStorage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorage) EXPECT() *MockStorage_Expecter {
+	return &MockStorage_Expecter{mock: &_m.Mock}
+}
+
+// CheckMigrations provides a mock function with given fields: ctx
+func (_m *MockStorage) CheckMigrations(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckMigrations")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_CheckMigrations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckMigrations'
+type MockStorage_CheckMigrations_Call struct {
+	*mock.Call
+}
+
+// CheckMigrations is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockStorage_Expecter) CheckMigrations(ctx interface{}) *MockStorage_CheckMigrations_Call {
+	return &MockStorage_CheckMigrations_Call{Call: _e.mock.On("CheckMigrations", ctx)}
+}
+
+func (_c *MockStorage_CheckMigrations_Call) Run(run func(ctx context.Context)) *MockStorage_CheckMigrations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockStorage_CheckMigrations_Call) Return(_a0 error) *MockStorage_CheckMigrations_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_CheckMigrations_Call) RunAndReturn(run func(context.Context) error) *MockStorage_CheckMigrations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *MockStorage) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type MockStorage_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockStorage_Expecter) Ping(ctx interface{}) *MockStorage_Ping_Call {
+	return &MockStorage_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *MockStorage_Ping_Call) Run(run func(ctx context.Context)) *MockStorage_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockStorage_Ping_Call) Return(_a0 error) *MockStorage_Ping_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_Ping_Call) RunAndReturn(run func(context.Context) error) *MockStorage_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorage creates a new instance of MockStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorage {
+	mock := &MockStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Спецификация пишется вручную; router_test сверяет ее с маршрутами
+// и типами запросов и ответов, чтобы она не отставала от кода.
+//
+//go:embed openapi.json
+var spec []byte
+
+//go:embed swagger.html
+var uiPage string
+
+var uiTemplate = template.Must(template.New("swagger").Parse(uiPage))
+
+// Spec возвращает документ OpenAPI 3 в JSON.
+func Spec() []byte {
+	return spec
+}
+
+// New отдает спецификацию. Маршрут регистрируется как /openapi, потому что
+// middleware.URLFormat отрезает расширение: других форматов, кроме json, нет.
+func New() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+		if format != "" && format != "json" {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(spec)))
+		_, _ = w.Write(spec)
+	}
+}
+
+// NewUI отдает страницу Swagger UI для спецификации по адресу specURL.
+// Сама страница встроена в бинарник, скрипты и стили грузятся с CDN.
+func NewUI(specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = uiTemplate.Execute(w, struct{ SpecURL string }{specURL})
+	}
+}
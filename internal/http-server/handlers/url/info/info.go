@@ -0,0 +1,139 @@
+package info
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response = api.Link
+
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.Link, error)
+}
+
+func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.info.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		// Информацию о ссылке обычно запрашивает ее создатель сразу после
+		// /saveURL, поэтому читаем из primary: реплика может еще отставать
+		ctx := storage.WithPrimary(r.Context())
+
+		link, err := urlGetter.GetURL(ctx, alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			log.Info("request canceled by client", sl.Err(err))
+			render.Status(r, resp.StatusClientClosedRequest)
+			render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Error("storage timeout", sl.Err(err))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+			return
+		}
+
+		w.Header().Set("ETag", ETag(link))
+		render.JSON(w, r, NewResponse(link))
+	}
+}
+
+// NewResponse описывает ссылку так, как ее видит клиент API:
+// без хэша пароля и с пустыми полями вместо нулевых значений.
+// ETag - версия ссылки в заголовках ETag и If-Match: PATCH с If-Match
+// меняет ссылку, только если ее не изменили после чтения.
+func ETag(link storage.Link) string {
+	return `"` + strconv.Itoa(link.Version) + `"`
+}
+
+func NewResponse(link storage.Link) Response {
+	res := Response{
+		Response:     resp.OK(),
+		Alias:        link.Alias,
+		URL:          link.URL,
+		Title:        link.Title,
+		RedirectType: link.RedirectType,
+
+		Owner: link.Owner,
+
+		ForwardQuery:  link.ForwardQuery,
+		QueryConflict: link.QueryConflict,
+		ForwardPath:   link.ForwardPath,
+
+		UTMTemplate: link.UTMTemplate,
+
+		PasswordProtected: link.PasswordHash != "",
+
+		MaxClicks: link.MaxClicks,
+
+		PendingURL: link.PendingURL,
+		ExpiredURL: link.ExpiredURL,
+
+		Targeting: link.Targeting,
+		Variants:  link.Variants,
+
+		Interstitial: link.Interstitial,
+	}
+	if link.OG != (storage.OpenGraph{}) {
+		res.OG = &link.OG
+	}
+	if !link.CreatedAt.IsZero() {
+		res.CreatedAt = &link.CreatedAt
+	}
+	if !link.ActiveFrom.IsZero() {
+		res.ActiveFrom = &link.ActiveFrom
+	}
+	if !link.ActiveUntil.IsZero() {
+		res.ActiveUntil = &link.ActiveUntil
+	}
+	if link.MaxClicks > 0 {
+		res.ClicksRemaining = &link.ClicksRemaining
+	}
+	if link.UTM != (storage.UTM{}) {
+		res.UTM = &link.UTM
+	}
+
+	return res
+}
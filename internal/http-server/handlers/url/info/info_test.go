@@ -0,0 +1,86 @@
+package info
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInfoHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		alias        string
+		mockURL      string
+		redirectType int
+		mockError    error
+		expectedCode int
+		expectedBody string
+		expectedETag string
+	}{
+		{
+			name:         "success",
+			alias:        "test_alias",
+			mockURL:      "https://github.com/",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","alias":"test_alias","url":"https://github.com/"}`,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "success: with redirect type",
+			alias:        "vanity",
+			mockURL:      "https://github.com/",
+			redirectType: http.StatusMovedPermanently,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","alias":"vanity","url":"https://github.com/","redirect_type":301}`,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "url not found",
+			alias:        "missing_alias",
+			mockError:    storage.ErrURLNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":"Error","error":"not found","code":"not_found"}`,
+		},
+		{
+			name:         "internal error",
+			alias:        "test_error",
+			mockError:    errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":"Error","error":"internal error","code":"internal_error"}`,
+		},
+	}
+
+	mockLog := slogdiscard.NewDiscardLogger()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+
+			// Чтение должно идти в primary, чтобы создатель сразу видел свою ссылку
+			primaryCtx := mock.MatchedBy(func(ctx context.Context) bool {
+				return storage.UsePrimary(ctx)
+			})
+			link := storage.Link{Alias: tc.alias, URL: tc.mockURL, RedirectType: tc.redirectType, Version: 2}
+			mockURLGetter.On("GetURL", primaryCtx, tc.alias).Return(link, tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Get("/url/{alias}", New(mockLog, mockURLGetter))
+
+			req := httptest.NewRequest(http.MethodGet, "/url/"+tc.alias, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			assert.Equal(t, tc.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
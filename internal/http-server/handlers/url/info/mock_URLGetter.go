@@ -0,0 +1,94 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package info
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockURLGetter is an autogenerated mock type for the URLGetter type
+type MockURLGetter struct {
+	mock.Mock
+}
+
+type MockURLGetter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockURLGetter) EXPECT() *MockURLGetter_Expecter {
+	return &MockURLGetter_Expecter{mock: &_m.Mock}
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockURLGetter) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURL")
+	}
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockURLGetter_GetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURL'
+type MockURLGetter_GetURL_Call struct {
+	*mock.Call
+}
+
+// GetURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockURLGetter_Expecter) GetURL(ctx interface{}, alias interface{}) *MockURLGetter_GetURL_Call {
+	return &MockURLGetter_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
+}
+
+func (_c *MockURLGetter_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockURLGetter_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockURLGetter_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockURLGetter_GetURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockURLGetter_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockURLGetter_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockURLGetter creates a new instance of MockURLGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockURLGetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockURLGetter {
+	mock := &MockURLGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,121 @@
+package list
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/info"
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Размер страницы по умолчанию и наибольший, как в gRPC ListLinks.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+type Response = api.LinkList
+
+type URLLister interface {
+	ListURLs(ctx context.Context, filter storage.ListFilter) ([]storage.Link, error)
+}
+
+// New отдает страницу списка ссылок. Параметры: owner - только ссылки
+// этого владельца, page_size - размер страницы, page_token - значение
+// next_page_token из предыдущего ответа.
+func New(log *slog.Logger, lister URLLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		filter, err := parseParams(r)
+		if err != nil {
+			log.Info("invalid list params", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidParameter, err.Error()))
+
+			return
+		}
+		size := filter.Limit
+
+		// Лишняя ссылка показывает, есть ли следующая страница
+		filter.Limit++
+
+		links, err := lister.ListURLs(r.Context(), filter)
+		if errors.Is(err, context.Canceled) {
+			log.Info("request canceled by client", sl.Err(err))
+			render.Status(r, resp.StatusClientClosedRequest)
+			render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Error("storage timeout", sl.Err(err))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to list urls", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+			return
+		}
+
+		res := Response{
+			Response: resp.OK(),
+			Links:    make([]api.Link, 0, len(links)),
+		}
+		if len(links) > size {
+			links = links[:size]
+			res.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(links[size-1].Alias))
+		}
+		for _, link := range links {
+			res.Links = append(res.Links, info.NewResponse(link))
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+func parseParams(r *http.Request) (storage.ListFilter, error) {
+	query := r.URL.Query()
+
+	filter := storage.ListFilter{
+		Owner: query.Get("owner"),
+		Limit: defaultPageSize,
+	}
+
+	if v := query.Get("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return storage.ListFilter{}, fmt.Errorf("page_size must be a positive number")
+		}
+		filter.Limit = min(size, maxPageSize)
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(query.Get("page_token"))
+	if err != nil {
+		return storage.ListFilter{}, fmt.Errorf("page_token is invalid")
+	}
+	filter.After = string(after)
+
+	return filter, nil
+}
@@ -0,0 +1,109 @@
+package list
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListHandler(t *testing.T) {
+	links := []storage.Link{
+		{Alias: "a", URL: "https://example.com/a"},
+		{Alias: "b", URL: "https://example.com/b"},
+		{Alias: "c", URL: "https://example.com/c"},
+	}
+
+	cases := []struct {
+		name         string
+		query        string
+		filter       *storage.ListFilter
+		links        []storage.Link
+		listError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "last page",
+			filter:       &storage.ListFilter{Limit: defaultPageSize + 1},
+			links:        links[:2],
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","links":[
+				{"status":"OK","alias":"a","url":"https://example.com/a"},
+				{"status":"OK","alias":"b","url":"https://example.com/b"}
+			]}`,
+		},
+		{
+			name:         "next page token",
+			query:        "?page_size=2&owner=admin",
+			filter:       &storage.ListFilter{Owner: "admin", Limit: 3},
+			links:        links,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","links":[
+				{"status":"OK","alias":"a","url":"https://example.com/a"},
+				{"status":"OK","alias":"b","url":"https://example.com/b"}
+			],"next_page_token":"Yg"}`,
+		},
+		{
+			name:         "page token",
+			query:        "?page_token=Yg",
+			filter:       &storage.ListFilter{After: "b", Limit: defaultPageSize + 1},
+			links:        links[2:],
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","links":[{"status":"OK","alias":"c","url":"https://example.com/c"}]}`,
+		},
+		{
+			name:         "empty list",
+			filter:       &storage.ListFilter{Limit: defaultPageSize + 1},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","links":[]}`,
+		},
+		{
+			name:         "page size is capped",
+			query:        "?page_size=5000",
+			filter:       &storage.ListFilter{Limit: maxPageSize + 1},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","links":[]}`,
+		},
+		{
+			name:         "invalid page size",
+			query:        "?page_size=0",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":"Error","error":"page_size must be a positive number","code":"invalid_parameter"}`,
+		},
+		{
+			name:         "invalid page token",
+			query:        "?page_token=%21",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":"Error","error":"page_token is invalid","code":"invalid_parameter"}`,
+		},
+		{
+			name:         "storage error",
+			filter:       &storage.ListFilter{Limit: defaultPageSize + 1},
+			listError:    errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":"Error","error":"internal error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLister := NewMockURLLister(t)
+			if tc.filter != nil {
+				mockLister.On("ListURLs", mock.Anything, *tc.filter).Return(tc.links, tc.listError).Once()
+			}
+
+			w := httptest.NewRecorder()
+			New(slogdiscard.NewDiscardLogger(), mockLister).
+				ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/links"+tc.query, nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package list
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockURLLister is an autogenerated mock type for the URLLister type
+type MockURLLister struct {
+	mock.Mock
+}
+
+type MockURLLister_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockURLLister) EXPECT() *MockURLLister_Expecter {
+	return &MockURLLister_Expecter{mock: &_m.Mock}
+}
+
+// ListURLs provides a mock function with given fields: ctx, filter
+func (_m *MockURLLister) ListURLs(ctx context.Context, filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListURLs")
+	}
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) []storage.Link); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockURLLister_ListURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListURLs'
+type MockURLLister_ListURLs_Call struct {
+	*mock.Call
+}
+
+// ListURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter storage.ListFilter
+func (_e *MockURLLister_Expecter) ListURLs(ctx interface{}, filter interface{}) *MockURLLister_ListURLs_Call {
+	return &MockURLLister_ListURLs_Call{Call: _e.mock.On("ListURLs", ctx, filter)}
+}
+
+func (_c *MockURLLister_ListURLs_Call) Run(run func(ctx context.Context, filter storage.ListFilter)) *MockURLLister_ListURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.ListFilter))
+	})
+	return _c
+}
+
+func (_c *MockURLLister_ListURLs_Call) Return(_a0 []storage.Link, _a1 error) *MockURLLister_ListURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockURLLister_ListURLs_Call) RunAndReturn(run func(context.Context, storage.ListFilter) ([]storage.Link, error)) *MockURLLister_ListURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockURLLister creates a new instance of MockURLLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockURLLister(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockURLLister {
+	mock := &MockURLLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,224 @@
+package qr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/qr"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Ограничения параметров: код больше 4096 пикселей уже никто не печатает,
+// а рисовать его на каждый запрос дорого.
+const (
+	defaultSize   = 256
+	minSize       = 64
+	maxSize       = 4096
+	defaultMargin = 4
+	maxMargin     = 16
+)
+
+type URLGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.Link, error)
+}
+
+// New отдает QR-код короткой ссылки. Формат - ?format=png|svg или
+// расширение /url/{alias}/qr.svg (его разбирает middleware.URLFormat).
+// baseURL - публичный адрес сервиса; если пуст, берется из запроса.
+func New(log *slog.Logger, urlGetter URLGetter, baseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.qr.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		format, opts, err := parseParams(r)
+		if err != nil {
+			log.Info("invalid qr params", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidParameter, err.Error()))
+
+			return
+		}
+
+		_, err = urlGetter.GetURL(r.Context(), alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			log.Info("request canceled by client", sl.Err(err))
+			render.Status(r, resp.StatusClientClosedRequest)
+			render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Error("storage timeout", sl.Err(err))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+			return
+		}
+
+		shortURL := shortURL(r, baseURL, alias)
+
+		// В коде зашит короткий адрес, а не адрес назначения, поэтому картинка
+		// не меняется при правке ссылки и ее можно кэшировать надолго
+		etag := etag(shortURL, format, opts)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		var (
+			body        []byte
+			contentType string
+		)
+		switch format {
+		case "svg":
+			body, err = qr.SVG(shortURL, opts)
+			contentType = "image/svg+xml"
+		default:
+			body, err = qr.PNG(shortURL, opts)
+			contentType = "image/png"
+		}
+		if err != nil {
+			// Размер проверен заранее, но мелкий код с длинным адресом
+			// может не поместиться: это ошибка запроса, а не сервера
+			log.Info("failed to render qr code", sl.Err(err))
+			w.Header().Del("ETag")
+			w.Header().Del("Cache-Control")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidParameter, "failed to render qr code: increase size"))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if _, err := w.Write(body); err != nil {
+			log.Info("failed to write qr code", sl.Err(err))
+		}
+	}
+}
+
+func parseParams(r *http.Request) (string, qr.Options, error) {
+	query := r.URL.Query()
+
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format, _ = r.Context().Value(middleware.URLFormatCtxKey).(string)
+	}
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		return "", qr.Options{}, fmt.Errorf("format must be one of: png svg")
+	}
+
+	opts := qr.Options{
+		Size:   defaultSize,
+		Level:  qr.LevelMedium,
+		Margin: defaultMargin,
+	}
+
+	var err error
+	if v := query.Get("size"); v != "" {
+		opts.Size, err = strconv.Atoi(v)
+		if err != nil || opts.Size < minSize || opts.Size > maxSize {
+			return "", qr.Options{}, fmt.Errorf("size must be between %d and %d", minSize, maxSize)
+		}
+	}
+	if v := query.Get("ecc"); v != "" {
+		opts.Level = strings.ToUpper(v)
+		switch opts.Level {
+		case qr.LevelLow, qr.LevelMedium, qr.LevelQuartile, qr.LevelHigh:
+		default:
+			return "", qr.Options{}, fmt.Errorf("ecc must be one of: L M Q H")
+		}
+	}
+	if v := query.Get("margin"); v != "" {
+		opts.Margin, err = strconv.Atoi(v)
+		if err != nil || opts.Margin < 0 || opts.Margin > maxMargin {
+			return "", qr.Options{}, fmt.Errorf("margin must be between 0 and %d", maxMargin)
+		}
+	}
+
+	opts.Foreground, err = qr.ParseColor(valueOr(query.Get("fg"), "000000"))
+	if err != nil {
+		return "", qr.Options{}, fmt.Errorf("fg: %w", err)
+	}
+	opts.Background, err = qr.ParseColor(valueOr(query.Get("bg"), "ffffff"))
+	if err != nil {
+		return "", qr.Options{}, fmt.Errorf("bg: %w", err)
+	}
+
+	return format, opts, nil
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+
+	return v
+}
+
+func shortURL(r *http.Request, baseURL, alias string) string {
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+
+	return strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(alias)
+}
+
+// etag однозначно определяется содержимым кода и параметрами отрисовки.
+func etag(shortURL, format string, opts qr.Options) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%d|%v|%v",
+		shortURL, format, opts.Size, opts.Level, opts.Margin, opts.Foreground, opts.Background)))
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
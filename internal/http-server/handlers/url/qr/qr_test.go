@@ -0,0 +1,155 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		target       string
+		mockError    error
+		skipGet      bool
+		expectedCode int
+		expectedType string
+	}{
+		{
+			name:         "png by default",
+			target:       "/url/promo/qr",
+			expectedCode: http.StatusOK,
+			expectedType: "image/png",
+		},
+		{
+			name:         "svg by query",
+			target:       "/url/promo/qr?format=svg&fg=%23336699&bg=fff&ecc=h&margin=2",
+			expectedCode: http.StatusOK,
+			expectedType: "image/svg+xml",
+		},
+		{
+			name:         "svg by extension",
+			target:       "/url/promo/qr.svg",
+			expectedCode: http.StatusOK,
+			expectedType: "image/svg+xml",
+		},
+		{
+			name:         "size out of range",
+			target:       "/url/promo/qr?size=10",
+			skipGet:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown format",
+			target:       "/url/promo/qr?format=gif",
+			skipGet:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid color",
+			target:       "/url/promo/qr?fg=blue",
+			skipGet:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "url not found",
+			target:       "/url/promo/qr",
+			mockError:    storage.ErrURLNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "internal error",
+			target:       "/url/promo/qr",
+			mockError:    errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+			if !tc.skipGet {
+				mockURLGetter.On("GetURL", mock.Anything, "promo").
+					Return(storage.Link{Alias: "promo", URL: "https://example.com/"}, tc.mockError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Use(middleware.URLFormat)
+			r.Get("/url/{alias}/qr", New(slogdiscard.NewDiscardLogger(), mockURLGetter, "https://sho.rt"))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedType != "" {
+				assert.Equal(t, tc.expectedType, w.Header().Get("Content-Type"))
+				assert.NotEmpty(t, w.Header().Get("ETag"))
+				assert.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+			}
+		})
+	}
+}
+
+func TestQRHandler_PNGSize(t *testing.T) {
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "promo").Return(storage.Link{Alias: "promo"}, nil).Once()
+
+	r := chi.NewRouter()
+	r.Get("/url/{alias}/qr", New(slogdiscard.NewDiscardLogger(), mockURLGetter, "https://sho.rt"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/url/promo/qr?size=512", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 512, img.Bounds().Dx())
+}
+
+func TestQRHandler_NotModified(t *testing.T) {
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "promo").Return(storage.Link{Alias: "promo"}, nil).Twice()
+
+	r := chi.NewRouter()
+	r.Get("/url/{alias}/qr", New(slogdiscard.NewDiscardLogger(), mockURLGetter, "https://sho.rt"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/url/promo/qr", nil))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/url/promo/qr", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	// Другие параметры - другая картинка и другой ETag
+	w = httptest.NewRecorder()
+	r2 := httptest.NewRequest(http.MethodGet, "/url/promo/qr?size=300", nil)
+	r2.Header.Set("If-None-Match", etag)
+	mockURLGetter.On("GetURL", mock.Anything, "promo").Return(storage.Link{Alias: "promo"}, nil).Once()
+	r.ServeHTTP(w, r2)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestShortURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/url/a/qr", nil)
+
+	assert.Equal(t, "https://sho.rt/a%20b", shortURL(req, "https://sho.rt/", "a b"))
+	assert.Equal(t, "http://localhost:8080/promo", shortURL(req, "", "promo"))
+}
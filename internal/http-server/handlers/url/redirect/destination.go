@@ -0,0 +1,146 @@
+package redirect
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// errUnsafePath - остаток пути выводит за пределы адреса назначения.
+var errUnsafePath = errors.New("path escapes the destination")
+
+// destination собирает итоговый адрес редиректа: к сохраненному URL
+// дописываются UTM-метки, а также остаток пути и query-параметры запроса,
+// если ссылка это разрешает. Сохраненный адрес при этом остается чистым.
+func destination(link storage.Link, utm storage.UTM, r *http.Request) (string, error) {
+	extra := extraPath(r)
+	incoming := r.URL.Query()
+	utmParams := utm.Params()
+
+	if (extra == "" || !link.ForwardPath) && (len(incoming) == 0 || !link.ForwardQuery) && len(utmParams) == 0 {
+		// Нечего дописывать: отдаем адрес как есть, не перекодируя его
+		return link.URL, nil
+	}
+
+	dest, err := url.Parse(link.URL)
+	if err != nil {
+		return "", fmt.Errorf("parse destination: %w", err)
+	}
+
+	if link.ForwardPath && extra != "" {
+		if dest, err = joinPath(dest, extra); err != nil {
+			return "", err
+		}
+	}
+
+	// Исходную query-строку не перекодируем: сайты бывают чувствительны
+	// к порядку параметров и к их кодировке. Только дописываем параметры
+	// и убираем те, что заменяются
+	params := splitQuery(dest.RawQuery)
+
+	// UTM-метки ссылки считаются частью адреса назначения,
+	// поэтому к ним применяется та же политика конфликтов, что и к адресу
+	for _, key := range slices.Sorted(maps.Keys(utmParams)) {
+		params = append(removeParam(params, key), encodeParam(key, utmParams[key]))
+	}
+
+	if link.ForwardQuery {
+		for _, key := range slices.Sorted(maps.Keys(incoming)) {
+			params = mergeParam(params, key, incoming[key], link.QueryConflict)
+		}
+	}
+
+	dest.RawQuery = strings.Join(params, "&")
+
+	return dest.String(), nil
+}
+
+// joinPath дописывает остаток пути к адресу назначения. Сегменты . и ..
+// (в том числе закодированные) не принимаются: иначе /{alias}/../admin
+// увел бы посетителя выше пути, который задал владелец ссылки.
+func joinPath(dest *url.URL, extra string) (*url.URL, error) {
+	for _, segment := range strings.Split(extra, "/") {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			decoded = segment
+		}
+		if segment == "." || segment == ".." || decoded == "." || decoded == ".." {
+			return nil, errUnsafePath
+		}
+	}
+
+	base := strings.TrimSuffix(dest.Path, "/")
+	joined := dest.JoinPath(extra)
+	if !strings.HasPrefix(joined.Path, base+"/") {
+		return nil, errUnsafePath
+	}
+
+	return joined, nil
+}
+
+// extraPath возвращает часть пути после алиаса: /{alias}/rest/of/path -> rest/of/path.
+// chi.URLParam(r, "*") не подходит: middleware.URLFormat отрезает расширение
+// у последнего сегмента, и /{alias}/file.pdf превратился бы в file.
+func extraPath(r *http.Request) string {
+	_, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	return rest
+}
+
+// mergeParam добавляет значения параметра из запроса по политике conflict.
+func mergeParam(params []string, key string, values []string, conflict string) []string {
+	if hasParam(params, key) {
+		switch conflict {
+		case storage.QueryConflictOverride:
+			params = removeParam(params, key)
+		case storage.QueryConflictAppend:
+		default:
+			// storage.QueryConflictKeep: значение из адреса назначения важнее
+			return params
+		}
+	}
+
+	for _, value := range values {
+		params = append(params, encodeParam(key, value))
+	}
+
+	return params
+}
+
+// splitQuery делит query-строку на пары key=value как есть, без декодирования.
+func splitQuery(raw string) []string {
+	var params []string
+	for _, param := range strings.Split(raw, "&") {
+		if param != "" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+func paramKey(param string) string {
+	key, _, _ := strings.Cut(param, "=")
+	if decoded, err := url.QueryUnescape(key); err == nil {
+		return decoded
+	}
+
+	return key
+}
+
+func hasParam(params []string, key string) bool {
+	return slices.ContainsFunc(params, func(param string) bool { return paramKey(param) == key })
+}
+
+func removeParam(params []string, key string) []string {
+	return slices.DeleteFunc(params, func(param string) bool { return paramKey(param) == key })
+}
+
+func encodeParam(key, value string) string {
+	return url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
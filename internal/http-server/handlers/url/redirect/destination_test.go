@@ -0,0 +1,143 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestination(t *testing.T) {
+	cases := []struct {
+		name     string
+		link     storage.Link
+		utm      storage.UTM
+		target   string
+		expected string
+	}{
+		{
+			name:     "query dropped by default",
+			link:     storage.Link{URL: "https://example.com/page?a=1"},
+			target:   "/alias?utm_source=x",
+			expected: "https://example.com/page?a=1",
+		},
+		{
+			name:     "query merged",
+			link:     storage.Link{URL: "https://example.com/page?a=1", ForwardQuery: true},
+			target:   "/alias?utm_source=x",
+			expected: "https://example.com/page?a=1&utm_source=x",
+		},
+		{
+			name:     "conflict: keep destination value",
+			link:     storage.Link{URL: "https://example.com/?a=1", ForwardQuery: true, QueryConflict: storage.QueryConflictKeep},
+			target:   "/alias?a=2&b=3",
+			expected: "https://example.com/?a=1&b=3",
+		},
+		{
+			name:     "conflict: empty policy keeps destination value",
+			link:     storage.Link{URL: "https://example.com/?a=1", ForwardQuery: true},
+			target:   "/alias?a=2",
+			expected: "https://example.com/?a=1",
+		},
+		{
+			name:     "conflict: override with incoming value",
+			link:     storage.Link{URL: "https://example.com/?a=1", ForwardQuery: true, QueryConflict: storage.QueryConflictOverride},
+			target:   "/alias?a=2",
+			expected: "https://example.com/?a=2",
+		},
+		{
+			name:     "conflict: append both values",
+			link:     storage.Link{URL: "https://example.com/?a=1", ForwardQuery: true, QueryConflict: storage.QueryConflictAppend},
+			target:   "/alias?a=2",
+			expected: "https://example.com/?a=1&a=2",
+		},
+		{
+			name:     "path appended",
+			link:     storage.Link{URL: "https://example.com/docs", ForwardPath: true},
+			target:   "/alias/guide/install.html",
+			expected: "https://example.com/docs/guide/install.html",
+		},
+		{
+			name:     "path appended to destination with trailing slash",
+			link:     storage.Link{URL: "https://example.com/docs/", ForwardPath: true},
+			target:   "/alias/guide",
+			expected: "https://example.com/docs/guide",
+		},
+		{
+			name:     "path and query together",
+			link:     storage.Link{URL: "https://example.com/docs?lang=en", ForwardPath: true, ForwardQuery: true},
+			target:   "/alias/guide?page=2",
+			expected: "https://example.com/docs/guide?lang=en&page=2",
+		},
+		{
+			name:     "utm appended",
+			link:     storage.Link{URL: "https://example.com/page?a=1"},
+			utm:      storage.UTM{Source: "newsletter", Medium: "email", Campaign: "spring sale"},
+			target:   "/alias",
+			expected: "https://example.com/page?a=1&utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:     "utm wins over forwarded query by default",
+			link:     storage.Link{URL: "https://example.com/", ForwardQuery: true},
+			utm:      storage.UTM{Source: "newsletter"},
+			target:   "/alias?utm_source=x&ref=1",
+			expected: "https://example.com/?utm_source=newsletter&ref=1",
+		},
+		{
+			name: "forwarded query overrides utm",
+			link: storage.Link{
+				URL: "https://example.com/", ForwardQuery: true, QueryConflict: storage.QueryConflictOverride,
+			},
+			utm:      storage.UTM{Source: "newsletter"},
+			target:   "/alias?utm_source=x",
+			expected: "https://example.com/?utm_source=x",
+		},
+		{
+			name:     "destination query is not re-encoded",
+			link:     storage.Link{URL: "https://example.com/search?q=a+b&path=%2Fdocs&z=1&a", ForwardQuery: true},
+			utm:      storage.UTM{Source: "newsletter"},
+			target:   "/alias?page=2",
+			expected: "https://example.com/search?q=a+b&path=%2Fdocs&z=1&a&utm_source=newsletter&page=2",
+		},
+		{
+			name:     "overridden parameter is removed from the raw query",
+			link:     storage.Link{URL: "https://example.com/?x=%7E&utm_source=old&y=1", ForwardQuery: true, QueryConflict: storage.QueryConflictOverride},
+			utm:      storage.UTM{Source: "newsletter"},
+			target:   "/alias?y=2",
+			expected: "https://example.com/?x=%7E&utm_source=newsletter&y=2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+
+			dest, err := destination(tc.link, tc.utm, req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, dest)
+		})
+	}
+}
+
+func TestDestination_UnsafePath(t *testing.T) {
+	link := storage.Link{URL: "https://example.com/docs/public", ForwardPath: true}
+
+	for _, target := range []string{
+		"/alias/../admin",
+		"/alias/guide/../../admin",
+		"/alias/./guide",
+		"/alias/%2e%2e/admin",
+		"/alias/%252e%252e/admin",
+		"/alias/..%2Fadmin",
+	} {
+		t.Run(target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+
+			_, err := destination(link, storage.UTM{}, req)
+			assert.ErrorIs(t, err, errUnsafePath)
+		})
+	}
+}
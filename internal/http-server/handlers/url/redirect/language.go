@@ -0,0 +1,47 @@
+package redirect
+
+import (
+	"strconv"
+	"strings"
+)
+
+// preferredLanguage возвращает язык с наибольшим весом из Accept-Language
+// в нижнем регистре: "de-AT,de;q=0.9,en;q=0.8" -> "de-at".
+// При равных весах побеждает указанный раньше. "*" языком не считается.
+func preferredLanguage(header string) string {
+	var (
+		best  string
+		bestQ = 0.0
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+
+	return best
+}
+
+// matchLanguage сравнивает язык посетителя с языком правила без учета
+// регистра. Правило без региона ("pt") подходит для любого региона ("pt-br").
+func matchLanguage(rule, client string) bool {
+	rule = strings.ToLower(rule)
+
+	return client == rule || strings.HasPrefix(client, rule+"-")
+}
@@ -0,0 +1,36 @@
+package redirect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{header: "de-AT,de;q=0.9,en;q=0.8", want: "de-at"},
+		{header: "en;q=0.5, fr", want: "fr"},
+		{header: "ru, uk", want: "ru"},
+		{header: "*;q=1, es;q=0.3", want: "es"},
+		{header: "en;q=0", want: ""},
+		{header: "pt-BR;q=bogus, pt;q=0.4", want: "pt"},
+		{header: "", want: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.want, preferredLanguage(tc.header))
+		})
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	assert.True(t, matchLanguage("pt", "pt-br"))
+	assert.True(t, matchLanguage("pt-BR", "pt-br"))
+	assert.False(t, matchLanguage("pt-BR", "pt"))
+	assert.False(t, matchLanguage("pt", "ptx"))
+	assert.False(t, matchLanguage("de", ""))
+}
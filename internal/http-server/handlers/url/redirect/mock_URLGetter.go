@@ -2,7 +2,12 @@
 
 package redirect
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockURLGetter is an autogenerated mock type for the URLGetter type
 type MockURLGetter struct {
@@ -17,27 +22,84 @@ func (_m *MockURLGetter) EXPECT() *MockURLGetter_Expecter {
 	return &MockURLGetter_Expecter{mock: &_m.Mock}
 }
 
-// GetURL provides a mock function with given fields: alias
-func (_m *MockURLGetter) GetURL(alias string) (string, error) {
-	ret := _m.Called(alias)
+// ConsumeClick provides a mock function with given fields: ctx, alias
+func (_m *MockURLGetter) ConsumeClick(ctx context.Context, alias string) (int, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeClick")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockURLGetter_ConsumeClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeClick'
+type MockURLGetter_ConsumeClick_Call struct {
+	*mock.Call
+}
+
+// ConsumeClick is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockURLGetter_Expecter) ConsumeClick(ctx interface{}, alias interface{}) *MockURLGetter_ConsumeClick_Call {
+	return &MockURLGetter_ConsumeClick_Call{Call: _e.mock.On("ConsumeClick", ctx, alias)}
+}
+
+func (_c *MockURLGetter_ConsumeClick_Call) Run(run func(ctx context.Context, alias string)) *MockURLGetter_ConsumeClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockURLGetter_ConsumeClick_Call) Return(_a0 int, _a1 error) *MockURLGetter_ConsumeClick_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockURLGetter_ConsumeClick_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockURLGetter_ConsumeClick_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockURLGetter) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetURL")
 	}
 
-	var r0 string
+	var r0 storage.Link
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(alias)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(alias)
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(storage.Link)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(alias)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -51,24 +113,72 @@ type MockURLGetter_GetURL_Call struct {
 }
 
 // GetURL is a helper method to define mock.On call
+//   - ctx context.Context
 //   - alias string
-func (_e *MockURLGetter_Expecter) GetURL(alias interface{}) *MockURLGetter_GetURL_Call {
-	return &MockURLGetter_GetURL_Call{Call: _e.mock.On("GetURL", alias)}
+func (_e *MockURLGetter_Expecter) GetURL(ctx interface{}, alias interface{}) *MockURLGetter_GetURL_Call {
+	return &MockURLGetter_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
 }
 
-func (_c *MockURLGetter_GetURL_Call) Run(run func(alias string)) *MockURLGetter_GetURL_Call {
+func (_c *MockURLGetter_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockURLGetter_GetURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockURLGetter_GetURL_Call) Return(_a0 string, _a1 error) *MockURLGetter_GetURL_Call {
+func (_c *MockURLGetter_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockURLGetter_GetURL_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockURLGetter_GetURL_Call) RunAndReturn(run func(string) (string, error)) *MockURLGetter_GetURL_Call {
+func (_c *MockURLGetter_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockURLGetter_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordClick provides a mock function with given fields: ctx, click
+func (_m *MockURLGetter) RecordClick(ctx context.Context, click storage.Click) error {
+	ret := _m.Called(ctx, click)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Click) error); ok {
+		r0 = rf(ctx, click)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockURLGetter_RecordClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordClick'
+type MockURLGetter_RecordClick_Call struct {
+	*mock.Call
+}
+
+// RecordClick is a helper method to define mock.On call
+//   - ctx context.Context
+//   - click storage.Click
+func (_e *MockURLGetter_Expecter) RecordClick(ctx interface{}, click interface{}) *MockURLGetter_RecordClick_Call {
+	return &MockURLGetter_RecordClick_Call{Call: _e.mock.On("RecordClick", ctx, click)}
+}
+
+func (_c *MockURLGetter_RecordClick_Call) Run(run func(ctx context.Context, click storage.Click)) *MockURLGetter_RecordClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Click))
+	})
+	return _c
+}
+
+func (_c *MockURLGetter_RecordClick_Call) Return(_a0 error) *MockURLGetter_RecordClick_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockURLGetter_RecordClick_Call) RunAndReturn(run func(context.Context, storage.Click) error) *MockURLGetter_RecordClick_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,83 @@
+package redirect
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
+	"github.com/go-chi/render"
+)
+
+// prefersHTML решает, ждет ли клиент HTML, а не JSON. HTML отдается, только
+// если text/html назван явно и с большим весом, чем JSON: браузеры шлют
+// "text/html,...,*/*;q=0.8", а curl и API-клиенты - "*/*" или application/json.
+func prefersHTML(r *http.Request) bool {
+	var htmlQ, jsonQ, appQ, anyQ float64 = 0, -1, -1, -1
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		switch mediaType {
+		case "text/html":
+			htmlQ = q
+		case "application/json":
+			jsonQ = q
+		case "application/*":
+			appQ = q
+		case "*/*":
+			anyQ = q
+		}
+	}
+
+	// Самый точный диапазон, под который попадает JSON, определяет его вес
+	if jsonQ < 0 {
+		jsonQ = appQ
+	}
+	if jsonQ < 0 {
+		jsonQ = anyQ
+	}
+
+	return htmlQ > 0 && htmlQ > jsonQ
+}
+
+// renderError отвечает на ошибку страницей для браузеров и JSON для
+// остальных клиентов. code и msg уходят в JSON, page - на страницу.
+func renderError(
+	log *slog.Logger,
+	renderer PageRenderer,
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	code string,
+	msg string,
+	page pages.ErrorData,
+) {
+	w.Header().Add("Vary", "Accept")
+
+	if prefersHTML(r) {
+		err := renderer.Error(w, status, page)
+		if err == nil {
+			return
+		}
+
+		// Шаблон выполняется в буфер, поэтому ответ еще не начат
+		log.Error("failed to render error page", sl.Err(err))
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, resp.Error(code, msg))
+}
@@ -0,0 +1,137 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"
+
+func TestPrefersHTML(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: browserAccept, want: true},
+		{accept: "text/html", want: true},
+		{accept: "", want: false},
+		{accept: "*/*", want: false},
+		{accept: "application/json", want: false},
+		{accept: "application/json, text/html", want: false},
+		{accept: "text/html;q=0.5, application/json", want: false},
+		{accept: "text/html, application/*;q=0.9", want: true},
+		{accept: "text/html;q=0", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tc.accept)
+
+			assert.Equal(t, tc.want, prefersHTML(req))
+		})
+	}
+}
+
+func TestRedirectHandler_ErrorPages(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		link         storage.Link
+		mockError    error
+		accept       string
+		notFoundURL  string
+		expectedCode int
+		expectedType string
+		expectedBody string
+		expectedURL  string
+	}{
+		{
+			name:         "unknown alias in browser",
+			mockError:    storage.ErrURLNotFound,
+			accept:       browserAccept,
+			expectedCode: http.StatusNotFound,
+			expectedType: "text/html; charset=utf-8",
+			expectedBody: "Link not found",
+		},
+		{
+			name:         "unknown alias in api client",
+			mockError:    storage.ErrURLNotFound,
+			accept:       "*/*",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expectedBody: `{"status":"Error","error":"not found","code":"not_found"}`,
+		},
+		{
+			name:         "unknown alias with fallback url",
+			mockError:    storage.ErrURLNotFound,
+			accept:       browserAccept,
+			notFoundURL:  "https://example.com/",
+			expectedCode: http.StatusFound,
+			expectedURL:  "https://example.com/",
+		},
+		{
+			name:         "expired link in browser",
+			link:         storage.Link{URL: "https://example.com/", ActiveUntil: now.Add(-time.Hour)},
+			accept:       browserAccept,
+			notFoundURL:  "https://example.com/",
+			expectedCode: http.StatusGone,
+			expectedType: "text/html; charset=utf-8",
+			expectedBody: "no longer available",
+		},
+		{
+			name:         "pending link in browser",
+			link:         storage.Link{URL: "https://example.com/", ActiveFrom: now.Add(time.Hour)},
+			accept:       browserAccept,
+			expectedCode: http.StatusNotFound,
+			expectedType: "text/html; charset=utf-8",
+			expectedBody: "coming soon",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := tc.link
+			link.Alias = "promo"
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "promo").Return(link, tc.mockError).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:          http.StatusFound,
+				NotYetAvailableStatus:  http.StatusNotFound,
+				NotYetAvailableMessage: "coming soon",
+				NotFoundURL:            tc.notFoundURL,
+			}, func() time.Time { return now })
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+			req.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedURL != "" {
+				assert.Equal(t, tc.expectedURL, w.Header().Get("Location"))
+				return
+			}
+
+			assert.Contains(t, w.Header().Get("Content-Type"), tc.expectedType)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package redirect
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// renderOpenGraph отдает краулеру соцсети страницу с разметкой превью.
+// meta refresh на странице уводит на dest тех, кто все же ее открыл.
+func renderOpenGraph(log *slog.Logger, renderer PageRenderer, w http.ResponseWriter, link storage.Link, dest string) {
+	w.Header().Set("Cache-Control", "private, max-age=0")
+
+	err := renderer.OpenGraph(w, http.StatusOK, pages.OpenGraphData{
+		Title:       link.OG.Title,
+		Description: link.OG.Description,
+		Image:       link.OG.Image,
+		URL:         dest,
+	})
+	if err != nil {
+		log.Error("failed to render open graph page", sl.Err(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,78 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedirectHandler_OpenGraph(t *testing.T) {
+	const (
+		slackbot = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+		browser  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	)
+
+	og := storage.OpenGraph{
+		Title:       "Spring sale",
+		Description: "Up to 50% off",
+		Image:       "https://example.com/og.png",
+	}
+
+	cases := []struct {
+		name      string
+		og        storage.OpenGraph
+		userAgent string
+		expectOG  bool
+	}{
+		{name: "crawler gets meta tags", og: og, userAgent: slackbot, expectOG: true},
+		{name: "human is redirected", og: og, userAgent: browser},
+		{name: "crawler without og is redirected", userAgent: slackbot},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := storage.Link{Alias: "sale", URL: "https://example.com/sale", OG: tc.og, MaxClicks: 10}
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "sale").Return(link, nil).Once()
+			if !tc.expectOG {
+				mockURLGetter.On("ConsumeClick", mock.Anything, "sale").Return(9, nil).Once()
+				mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+			}, time.Now)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/sale", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if tc.expectOG {
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Contains(t, w.Body.String(), `<meta property="og:title" content="Spring sale">`)
+				assert.Contains(t, w.Body.String(), `<meta property="og:url" content="https://example.com/sale">`)
+			} else {
+				assert.Equal(t, http.StatusFound, w.Code)
+				assert.Equal(t, "https://example.com/sale", w.Header().Get("Location"))
+			}
+
+			if tc.og != (storage.OpenGraph{}) {
+				assert.Equal(t, "User-Agent", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package redirect
+
+import (
+	"embed"
+	"html/template"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// Ограничение на тело формы с паролем
+const maxPasswordFormSize = 4 << 10
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var passwordTmpl = template.Must(template.ParseFS(templatesFS, "templates/password.html"))
+
+type PasswordGuard interface {
+	Allow(r *http.Request, alias string) (bool, time.Duration)
+	Check(r *http.Request, alias, hash, password string) bool
+	HasAccess(r *http.Request, alias, hash string) bool
+	Grant(w http.ResponseWriter, r *http.Request, alias, hash string)
+}
+
+// checkPassword пропускает запрос к защищенной ссылке, только если у клиента
+// есть кука доступа. Иначе показывает форму ввода пароля (GET) или проверяет
+// присланный из нее пароль. Возвращает true, если можно делать редирект.
+func checkPassword(log *slog.Logger, guard PasswordGuard, w http.ResponseWriter, r *http.Request, link storage.Link) bool {
+	if guard.HasAccess(r, link.Alias, link.PasswordHash) {
+		return true
+	}
+
+	if r.Method == http.MethodGet {
+		renderPasswordForm(log, w, r, http.StatusOK, "")
+		return false
+	}
+
+	// Ссылки 307/308 принимают и обычные POST, PUT и т.д., которые должны
+	// уйти на адрес назначения. Попыткой ввода пароля считается только
+	// отправка формы: иначе чужие запросы расходовали бы лимит попыток
+	// ссылки и блокировали ее для всех
+	if !isPasswordForm(w, r) {
+		log.Info("password required", slog.String("alias", link.Alias))
+		renderPasswordForm(log, w, r, http.StatusUnauthorized, "Password required.")
+		return false
+	}
+
+	ok, retryAfter := guard.Allow(r, link.Alias)
+	if !ok {
+		log.Warn("too many password attempts", slog.String("alias", link.Alias))
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		renderPasswordForm(log, w, r, http.StatusTooManyRequests, "Too many attempts, try again later.")
+		return false
+	}
+
+	if !guard.Check(r, link.Alias, link.PasswordHash, r.PostFormValue("password")) {
+		log.Info("wrong link password", slog.String("alias", link.Alias))
+		renderPasswordForm(log, w, r, http.StatusForbidden, "Wrong password.")
+		return false
+	}
+
+	guard.Grant(w, r, link.Alias, link.PasswordHash)
+
+	// Возвращаем клиента на GET того же адреса: с кукой он получит обычный
+	// редирект. Сразу редиректить нельзя - при 307/308 браузер переслал бы
+	// форму с паролем на адрес назначения
+	http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+
+	return false
+}
+
+// isPasswordForm сообщает, что запрос - отправка формы пароля:
+// POST в application/x-www-form-urlencoded с полем password.
+func isPasswordForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/x-www-form-urlencoded" {
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPasswordFormSize)
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+
+	return r.PostForm.Has("password")
+}
+
+func renderPasswordForm(log *slog.Logger, w http.ResponseWriter, r *http.Request, status int, errMsg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	err := passwordTmpl.Execute(w, struct {
+		Action string
+		Error  string
+	}{
+		Action: r.URL.RequestURI(),
+		Error:  errMsg,
+	})
+	if err != nil {
+		log.Error("failed to render password form", sl.Err(err))
+	}
+}
@@ -0,0 +1,326 @@
+package redirect
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectHandler_Password(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockURLGetter.On("GetURL", mock.Anything, "secret").Return(storage.Link{
+		Alias:        "secret",
+		URL:          "https://example.com/private",
+		RedirectType: http.StatusPermanentRedirect,
+		PasswordHash: hash,
+	}, nil)
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus:        http.StatusFound,
+		PermanentCacheMaxAge: time.Hour,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+	r.Post("/{alias}", handler)
+
+	submit := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"password": {password}}
+		req := httptest.NewRequest(http.MethodPost, "/secret?ref=1", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// Без куки вместо редиректа показывается форма
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/secret?ref=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `action="/secret?ref=1"`)
+	assert.Empty(t, w.Header().Get("Location"))
+
+	// Неверный пароль
+	w = submit("wrong")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+
+	// Верный пароль: кука и возврат на GET того же адреса через 303,
+	// чтобы 308 не переслал форму с паролем на адрес назначения
+	w = submit("s3cret")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/secret?ref=1", w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// С кукой - обычный редирект, который не попадает в общие кэши
+	req := httptest.NewRequest(http.MethodGet, "/secret", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "https://example.com/private", w.Header().Get("Location"))
+	assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+
+	// Поддельная кука не принимается
+	req = httptest.NewRequest(http.MethodGet, "/secret", nil)
+	req.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: "9999999999.forged"})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
+func TestRedirectHandler_PasswordCookieName(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	// Буквы не из ASCII допустимы в алиасе, но не в имени куки,
+	// а алиасы, созданные до ограничения набора символов, содержат и пробелы
+	for _, alias := range []string{"секрет", "old docs"} {
+		t.Run(alias, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockURLGetter.On("GetURL", mock.Anything, alias).Return(storage.Link{
+				Alias:        alias,
+				URL:          "https://example.com/private",
+				PasswordHash: hash,
+			}, nil)
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+			}, time.Now)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+			r.Post("/{alias}", handler)
+
+			target := "/" + url.PathEscape(alias)
+
+			req := httptest.NewRequest(http.MethodPost, target, strings.NewReader("password=s3cret"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusSeeOther, w.Code)
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.NoError(t, cookies[0].Valid())
+
+			req = httptest.NewRequest(http.MethodGet, target, nil)
+			req.AddCookie(cookies[0])
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusFound, w.Code)
+			assert.Equal(t, "https://example.com/private", w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_PasswordRateLimit(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockURLGetter.On("GetURL", mock.Anything, "secret").Return(storage.Link{
+		Alias:        "secret",
+		URL:          "https://example.com/private",
+		PasswordHash: hash,
+	}, nil)
+
+	// newTestGuard разрешает 3 попытки в минуту
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Post("/{alias}", handler)
+
+	codes := make([]int, 0, 5)
+	for _, password := range []string{"a", "b", "c", "d", "s3cret"} {
+		req := httptest.NewRequest(http.MethodPost, "/secret", strings.NewReader("password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+
+		if w.Code == http.StatusTooManyRequests {
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		}
+	}
+
+	// Даже верный пароль не принимается, пока действует блокировка
+	assert.Equal(t, []int{
+		http.StatusForbidden, http.StatusForbidden, http.StatusForbidden,
+		http.StatusTooManyRequests, http.StatusTooManyRequests,
+	}, codes)
+}
+
+// Пароль, введенный на странице предпросмотра, должен открыть и ее:
+// браузер возвращается на GET /secret+ и отправляет выданную куку
+func TestRedirectHandler_PasswordPreview(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "secret").Return(storage.Link{
+		Alias:        "secret",
+		URL:          "https://example.com/private",
+		Title:        "Private docs",
+		PasswordHash: hash,
+	}, nil)
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+	r.Post("/{alias}", handler)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	res, err := client.PostForm(srv.URL+"/secret+", url.Values{"password": {"s3cret"}})
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	// Клиент прошел по 303 обратно на предпросмотр и получил его, а не форму
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, srv.URL+"/secret+", res.Request.URL.String())
+	assert.Contains(t, string(body), "Private docs")
+	assert.NotContains(t, string(body), `name="password"`)
+}
+
+func TestRedirectHandler_PasswordAliasLockout(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "secret").Return(storage.Link{
+		Alias:        "secret",
+		URL:          "https://example.com/private",
+		PasswordHash: hash,
+	}, nil)
+
+	guard, err := linkpassword.NewGuard(config.LinkPassword{
+		CookieSecret:     "test-secret",
+		CookieTTL:        time.Hour,
+		MaxAttempts:      3,
+		AttemptWindow:    time.Minute,
+		AliasMaxAttempts: 4,
+		AliasLockout:     time.Hour,
+	})
+	require.NoError(t, err)
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, guard, fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Post("/{alias}", handler)
+
+	// Каждая попытка - с нового адреса, лимит по адресу не срабатывает
+	codes := make([]int, 0, 6)
+	for i, password := range []string{"a", "b", "c", "d", "e", "s3cret"} {
+		req := httptest.NewRequest(http.MethodPost, "/secret", strings.NewReader("password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", i+1)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+
+		if w.Code == http.StatusTooManyRequests {
+			assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+		}
+	}
+
+	assert.Equal(t, []int{
+		http.StatusForbidden, http.StatusForbidden, http.StatusForbidden, http.StatusForbidden,
+		http.StatusTooManyRequests, http.StatusTooManyRequests,
+	}, codes)
+}
+
+func TestRedirectHandler_PasswordIgnoresForwardedRequests(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockURLGetter.On("GetURL", mock.Anything, "hook").Return(storage.Link{
+		Alias:        "hook",
+		URL:          "https://example.com/hook",
+		RedirectType: http.StatusTemporaryRedirect,
+		PasswordHash: hash,
+	}, nil)
+
+	guard, err := linkpassword.NewGuard(config.LinkPassword{
+		CookieSecret:     "test-secret",
+		CookieTTL:        time.Hour,
+		MaxAttempts:      3,
+		AttemptWindow:    time.Minute,
+		AliasMaxAttempts: 4,
+		AliasLockout:     time.Hour,
+	})
+	require.NoError(t, err)
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, guard, fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Post("/{alias}", handler)
+
+	post := func(contentType, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// JSON и формы без поля password - не попытки ввода пароля,
+	// сколько бы их ни было, и лимит ссылки они не расходуют
+	for range 10 {
+		assert.Equal(t, http.StatusUnauthorized, post("application/json", `{"password": "x"}`))
+		assert.Equal(t, http.StatusUnauthorized, post("application/x-www-form-urlencoded", "event=push"))
+	}
+
+	assert.Equal(t, http.StatusSeeOther, post("application/x-www-form-urlencoded; charset=utf-8", "password=s3cret"))
+}
@@ -0,0 +1,104 @@
+package redirect
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// PageRenderer рисует HTML-страницы вместо редиректа.
+type PageRenderer interface {
+	Preview(w http.ResponseWriter, status int, data pages.PreviewData) error
+	Interstitial(w http.ResponseWriter, status int, data pages.InterstitialData) error
+	OpenGraph(w http.ResponseWriter, status int, data pages.OpenGraphData) error
+	Error(w http.ResponseWriter, status int, data pages.ErrorData) error
+}
+
+// previewSuffix в конце алиаса (/{alias}+) включает предпросмотр.
+const previewSuffix = "+"
+
+// isPreview сообщает, просит ли посетитель предпросмотр ссылки
+// вместо перехода: /{alias}+ или ?preview=1.
+func isPreview(r *http.Request, alias string) (string, bool) {
+	if trimmed, ok := strings.CutSuffix(alias, previewSuffix); ok && trimmed != "" {
+		return trimmed, true
+	}
+
+	return alias, r.URL.Query().Get("preview") == "1"
+}
+
+func renderPreview(log *slog.Logger, renderer PageRenderer, w http.ResponseWriter, link storage.Link) {
+	// Страница не меняется от перехода к переходу, но может устареть
+	// после правки ссылки, поэтому кэшируется только в браузере
+	w.Header().Set("Cache-Control", "private, max-age=0")
+
+	err := renderer.Preview(w, http.StatusOK, pages.PreviewData{
+		Alias:     link.Alias,
+		Title:     link.Title,
+		URL:       link.URL,
+		CreatedAt: link.CreatedAt,
+		Owner:     link.Owner,
+		Dynamic:   len(link.Targeting) > 0 || len(link.Variants) > 0,
+	})
+	if err != nil {
+		log.Error("failed to render preview", sl.Err(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// needsInterstitial решает, показать ли перед переходом на dest страницу
+// "вы переходите на ...": так просит сама ссылка, ссылка создана недавно
+// или dest ведет на чужой сайт.
+func needsInterstitial(link storage.Link, dest string, cfg config.Interstitial, now time.Time) bool {
+	if link.Interstitial {
+		return true
+	}
+
+	if cfg.NewLinkAge > 0 && !link.CreatedAt.IsZero() && now.Sub(link.CreatedAt) < cfg.NewLinkAge {
+		return true
+	}
+
+	if cfg.External {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return true
+		}
+
+		return !isInternalHost(u.Hostname(), cfg.InternalHosts)
+	}
+
+	return false
+}
+
+func isInternalHost(host string, internal []string) bool {
+	host = strings.ToLower(host)
+	for _, h := range internal {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func renderInterstitial(log *slog.Logger, renderer PageRenderer, w http.ResponseWriter, link storage.Link, dest string) {
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	err := renderer.Interstitial(w, http.StatusOK, pages.InterstitialData{
+		Alias: link.Alias,
+		Title: link.Title,
+		URL:   dest,
+	})
+	if err != nil {
+		log.Error("failed to render interstitial", sl.Err(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,176 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedirectHandler_Preview(t *testing.T) {
+	link := storage.Link{
+		Alias:     "docs",
+		URL:       "https://example.com/docs",
+		Title:     "Product docs",
+		Owner:     "admin",
+		CreatedAt: time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC),
+		MaxClicks: 5,
+	}
+
+	for _, target := range []string{"/docs+", "/docs?preview=1"} {
+		t.Run(target, func(t *testing.T) {
+			// Ни ConsumeClick, ни RecordClick: предпросмотр - не переход
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+			}, time.Now)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Location"))
+			assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+			assert.Contains(t, w.Body.String(), "Product docs")
+			assert.Contains(t, w.Body.String(), "https://example.com/docs")
+			assert.Contains(t, w.Body.String(), "admin")
+			assert.Contains(t, w.Body.String(), "14 Mar 2025")
+		})
+	}
+}
+
+func TestRedirectHandler_PreviewOutsideWindow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		link storage.Link
+	}{
+		{
+			name: "pending",
+			link: storage.Link{ActiveFrom: now.Add(time.Hour)},
+		},
+		{
+			name: "pending with fallback",
+			link: storage.Link{ActiveFrom: now.Add(time.Hour), PendingURL: "https://example.com/soon"},
+		},
+		{
+			name: "expired",
+			link: storage.Link{ActiveUntil: now.Add(-time.Hour)},
+		},
+		{
+			name: "expired with fallback",
+			link: storage.Link{ActiveUntil: now.Add(-time.Hour), ExpiredURL: "https://example.com/gone"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := tc.link
+			link.Alias = "docs"
+			link.URL = "https://example.com/docs"
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:         http.StatusFound,
+				NotYetAvailableStatus: http.StatusServiceUnavailable,
+			}, func() time.Time { return now })
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs+", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Location"))
+			assert.Contains(t, w.Body.String(), "https://example.com/docs")
+		})
+	}
+}
+
+func TestRedirectHandler_Interstitial(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.Interstitial{
+		External:      true,
+		InternalHosts: []string{"example.com"},
+		NewLinkAge:    24 * time.Hour,
+	}
+
+	cases := []struct {
+		name         string
+		link         storage.Link
+		interstitial bool
+	}{
+		{
+			name: "internal old link redirects",
+			link: storage.Link{URL: "https://docs.example.com/", CreatedAt: now.Add(-48 * time.Hour)},
+		},
+		{
+			name: "legacy link without creation date redirects",
+			link: storage.Link{URL: "https://example.com/"},
+		},
+		{
+			name:         "external destination",
+			link:         storage.Link{URL: "https://evil.example.net/", CreatedAt: now.Add(-48 * time.Hour)},
+			interstitial: true,
+		},
+		{
+			name:         "new link",
+			link:         storage.Link{URL: "https://example.com/", CreatedAt: now.Add(-time.Hour)},
+			interstitial: true,
+		},
+		{
+			name:         "forced by link",
+			link:         storage.Link{URL: "https://example.com/", Interstitial: true},
+			interstitial: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := tc.link
+			link.Alias = "go"
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "go").Return(link, nil).Once()
+			// Клик учитывается и при показе страницы: переход идет по ссылке с нее
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+				Interstitial:  cfg,
+			}, func() time.Time { return now })
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/go", nil))
+
+			if tc.interstitial {
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Contains(t, w.Body.String(), "You are leaving to")
+				assert.Contains(t, w.Body.String(), `href="`+link.URL+`"`)
+				assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+			} else {
+				assert.Equal(t, http.StatusFound, w.Code)
+				assert.Equal(t, link.URL, w.Header().Get("Location"))
+			}
+		})
+	}
+}
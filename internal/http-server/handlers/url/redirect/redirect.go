@@ -1,12 +1,22 @@
 package redirect
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/Tbits007/url-shortener/internal/config"
 	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
 	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/lib/useragent"
 	"github.com/Tbits007/url-shortener/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,49 +24,319 @@ import (
 )
 
 type URLGetter interface {
-    GetURL(alias string) (string, error)
+    GetURL(ctx context.Context, alias string) (storage.Link, error)
+    ConsumeClick(ctx context.Context, alias string) (int, error)
+    RecordClick(ctx context.Context, click storage.Click) error
 }
 
-func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+// CountryResolver определяет страну посетителя (ISO 3166-1 alpha-2).
+// Пустая строка - страну определить не удалось.
+type CountryResolver interface {
+    Country(r *http.Request) string
+}
+
+// now позволяет подменить часы в тестах; в сервисе передается time.Now.
+func New(
+    log *slog.Logger,
+    urlGetter URLGetter,
+    guard PasswordGuard,
+    countries CountryResolver,
+    renderer PageRenderer,
+    cfg config.Redirect,
+    now func() time.Time,
+) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         const op = "handlers.url.redirect.New"
 
-        log = log.With(
+        log := log.With(
             slog.String("op", op),
             slog.String("request_id", middleware.GetReqID(r.Context())),
-        )		
+        ).With(tracing.LogAttrs(r.Context())...)
 
-        alias := chi.URLParam(r, "alias")
+        alias, preview := isPreview(r, chi.URLParam(r, "alias"))
         if alias == "" {
             log.Info("alias is empty")
-			render.Status(r, http.StatusNotFound)
-            render.JSON(w, r, resp.Error("not found"))
+            render.Status(r, http.StatusNotFound)
+            render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
 
             return
         }
-		
+
         // Находим URL по алиасу в БД
-        resURL, err := urlGetter.GetURL(alias)
+        link, err := urlGetter.GetURL(r.Context(), alias)
         if errors.Is(err, storage.ErrURLNotFound) {
             // Не нашли URL, сообщаем об этом клиенту
             log.Info("url not found", "alias", alias)
-			render.Status(r, http.StatusNotFound)
-            render.JSON(w, r, resp.Error("not found"))
+            if cfg.NotFoundURL != "" {
+                // Несуществующие алиасы уводим на запасной адрес, например на главную
+                fallbackRedirect(w, r, cfg.NotFoundURL)
+
+                return
+            }
+
+            renderError(log, renderer, w, r, http.StatusNotFound, resp.CodeNotFound, "not found", pages.ErrorData{Alias: alias})
+
+            return
+        }
+        if errors.Is(err, context.Canceled) {
+            // Клиент ушел, не дождавшись ответа
+            log.Info("request canceled by client", sl.Err(err))
+            render.Status(r, resp.StatusClientClosedRequest)
+            render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+
+            return
+        }
+        if errors.Is(err, context.DeadlineExceeded) {
+            // База не ответила за отведенное время
+            log.Error("storage timeout", sl.Err(err))
+            render.Status(r, http.StatusServiceUnavailable)
+            render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
 
             return
         }
         if err != nil {
             // Не удалось осуществить поиск
             log.Error("failed to get url", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-            render.JSON(w, r, resp.Error("internal error"))
+            render.Status(r, http.StatusInternalServerError)
+            render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+            return
+        }
+
+        log.Info("got url", slog.String("url", link.URL))
+
+        if extraPath(r) != "" && !link.ForwardPath {
+            // Ссылка не разрешает дописывать путь, /{alias}/... для нее не существует
+            log.Info("path passthrough is disabled", slog.String("alias", alias))
+            renderError(log, renderer, w, r, http.StatusNotFound, resp.CodeNotFound, "not found", pages.ErrorData{Alias: alias})
+
+            return
+        }
+
+        status := link.RedirectType
+        if status == 0 {
+            status = cfg.DefaultStatus
+        }
+
+        if allow := allowedMethods(link, status); !slices.Contains(allow, r.Method) {
+            w.Header().Set("Allow", strings.Join(allow, ", "))
+            render.Status(r, http.StatusMethodNotAllowed)
+            render.JSON(w, r, resp.Error(resp.CodeMethodNotAllowed, "method not allowed"))
 
             return
         }
 
-        log.Info("got url", slog.String("url", resURL))
+        if link.PasswordHash != "" {
+            // Защищенная ссылка: без куки доступа вместо редиректа форма пароля
+            if !checkPassword(log, guard, w, r, link) {
+                return
+            }
+        }
+
+        if preview {
+            // Предпросмотр не считается переходом: клики не списываются
+            log.Info("rendering preview", slog.String("alias", alias))
+            renderPreview(log, renderer, w, link)
+
+            return
+        }
+
+        // Окно активности проверяется после предпросмотра: по /{alias}+
+        // можно посмотреть, куда ведет еще не начавшая или уже истекшая ссылка
+        switch window(link, now()) {
+        case windowPending:
+            if link.PendingURL != "" {
+                log.Info("link is not active yet, redirecting to fallback", slog.String("alias", alias))
+                fallbackRedirect(w, r, link.PendingURL)
+
+                return
+            }
+
+            log.Info("link is not active yet", slog.String("alias", alias))
+            renderError(log, renderer, w, r, cfg.NotYetAvailableStatus, resp.CodeNotYetAvailable, cfg.NotYetAvailableMessage, pages.ErrorData{
+                Alias:   alias,
+                Message: cfg.NotYetAvailableMessage,
+            })
+
+            return
+        case windowExpired:
+            if link.ExpiredURL != "" {
+                log.Info("link has expired, redirecting to fallback", slog.String("alias", alias))
+                fallbackRedirect(w, r, link.ExpiredURL)
+
+                return
+            }
+
+            log.Info("link has expired", slog.String("alias", alias))
+            renderError(log, renderer, w, r, http.StatusGone, resp.CodeGone, "link is no longer available", pages.ErrorData{Alias: alias})
+
+            return
+        }
+
+        // Метки шаблона приходят вместе со ссылкой, свои метки ссылки важнее
+        utm := link.TemplateUTM.Merge(link.UTM)
+
+        visitor := client{
+            Info:     useragent.Parse(r.UserAgent()),
+            Country:  countries.Country(r),
+            Language: preferredLanguage(r.Header.Get("Accept-Language")),
+        }
+
+        targeted := false
+        if len(link.Targeting) > 0 {
+            // Адрес зависит от клиента, кэши должны различать эти заголовки.
+            // Страна по IP в Vary не выражается, поэтому кэш только private
+            w.Header().Add("Vary", "User-Agent, Accept-Language")
+
+            if rule, ok := matchTarget(link.Targeting, visitor); ok {
+                log.Info("targeting rule matched", slog.String("url", rule.URL))
+                link.URL = rule.URL
+                targeted = true
+            }
+        }
+
+        // A/B-тест делит только тех, кого не увело правило таргетинга
+        var variant string
+        if !targeted {
+            if v, ok := pickVariant(r, link); ok {
+                log.Info("variant picked", slog.String("variant", v.Name))
+                rememberVariant(w, r, link.Alias, v.Name, cfg.VariantCookieTTL)
+                link.URL = v.URL
+                variant = v.Name
+            }
+        }
+
+        dest, err := destination(link, utm, r)
+        if errors.Is(err, errUnsafePath) {
+            log.Info("unsafe passthrough path", slog.String("alias", alias), slog.String("path", extraPath(r)))
+            renderError(log, renderer, w, r, http.StatusNotFound, resp.CodeNotFound, "not found", pages.ErrorData{Alias: alias})
+
+            return
+        }
+        if err != nil {
+            log.Error("failed to build destination", sl.Err(err))
+            render.Status(r, http.StatusInternalServerError)
+            render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+            return
+        }
+
+        if link.OG != (storage.OpenGraph{}) {
+            if len(link.Targeting) == 0 {
+                w.Header().Add("Vary", "User-Agent")
+            }
+
+            if visitor.Social {
+                // Краулер строит превью и не должен тратить клики и статистику,
+                // а люди по-прежнему получают обычный редирект
+                log.Info("rendering open graph page", slog.String("alias", alias))
+                renderOpenGraph(log, renderer, w, link, dest)
+
+                return
+            }
+        }
+
+        if link.MaxClicks > 0 {
+            // Клик списывается последним, когда редирект точно состоится:
+            // показ формы пароля или ошибка не должны тратить переходы
+            remaining, err := urlGetter.ConsumeClick(r.Context(), link.Alias)
+            if errors.Is(err, storage.ErrClicksExhausted) {
+                log.Info("link clicks exhausted", slog.String("alias", alias))
+                renderError(log, renderer, w, r, http.StatusGone, resp.CodeGone, "link is no longer available", pages.ErrorData{Alias: alias})
+
+                return
+            }
+            if err != nil {
+                log.Error("failed to consume click", sl.Err(err))
+                render.Status(r, http.StatusInternalServerError)
+                render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+                return
+            }
+
+            log.Info("click consumed", slog.Int("remaining", remaining))
+        }
+
+        err = urlGetter.RecordClick(r.Context(), storage.Click{
+            Alias:    link.Alias,
+            At:       now(),
+            URL:      dest,
+            Variant:  variant,
+            Country:  visitor.Country,
+            Language: visitor.Language,
+            OS:       visitor.OS,
+            Device:   visitor.Device,
+            Bot:      visitor.Bot,
+        })
+        if err != nil {
+            // Потерянное событие статистики не повод не пускать посетителя
+            log.Warn("failed to record click", sl.Err(err))
+        }
+
+        if needsInterstitial(link, dest, cfg.Interstitial, now()) {
+            // Переход состоится по ссылке со страницы, поэтому клик уже учтен
+            log.Info("rendering interstitial", slog.String("alias", alias))
+            renderInterstitial(log, renderer, w, link, dest)
+
+            return
+        }
+
+        w.Header().Set("Cache-Control", cacheControl(link, status, cfg.PermanentCacheMaxAge, now()))
+
+        metrics.RedirectsTotal.Inc()
 
         // Делаем редирект на найденный URL
-        http.Redirect(w, r, resURL, http.StatusFound)
-    }		
-}
\ No newline at end of file
+        http.Redirect(w, r, dest, status)
+    }
+}
+
+// allowedMethods - методы, с которыми можно открыть ссылку. 307 и 308
+// сохраняют метод и тело, поэтому такие ссылки перенаправляют любой метод;
+// после 301/302/303 клиент придет на адрес назначения с GET, и остальные
+// методы отклоняются. POST защищенной ссылки - отправка формы пароля.
+func allowedMethods(link storage.Link, status int) []string {
+    if status == http.StatusTemporaryRedirect || status == http.StatusPermanentRedirect {
+        return []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+    }
+    if link.PasswordHash != "" {
+        return []string{http.MethodGet, http.MethodPost}
+    }
+
+    return []string{http.MethodGet}
+}
+
+// cacheControl разрешает кэшировать постоянные редиректы ограниченное время:
+// без max-age браузер запомнит 301 навсегда и ссылку уже не получится поменять.
+// Временные редиректы не кэшируются, чтобы каждый переход доходил до сервиса.
+func cacheControl(link storage.Link, status int, maxAge time.Duration, now time.Time) string {
+    if link.PasswordHash != "" || link.MaxClicks > 0 {
+        // Редирект за паролем или с лимитом кликов нельзя отдавать из кэшей:
+        // каждый переход должен дойти до сервиса
+        return "private, no-store"
+    }
+
+    if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+        return "private, max-age=0"
+    }
+
+    // Кэш не должен пережить окончание окна активности ссылки
+    if !link.ActiveUntil.IsZero() {
+        maxAge = min(maxAge, link.ActiveUntil.Sub(now))
+    }
+
+    // Адрес с таргетингом или вариантом A/B-теста выбран под конкретного
+    // клиента: его можно запомнить в браузере, но не в общем кэше
+    scope := "public"
+    if len(link.Targeting) > 0 || len(link.Variants) > 0 {
+        scope = "private"
+    }
+
+    return fmt.Sprintf("%s, max-age=%d", scope, int(maxAge.Seconds()))
+}
+
+// fallbackRedirect уводит на запасной адрес ссылки вне окна активности.
+// Ответ зависит от времени, поэтому не кэшируется.
+func fallbackRedirect(w http.ResponseWriter, r *http.Request, url string) {
+    w.Header().Set("Cache-Control", "private, no-store")
+    http.Redirect(w, r, url, http.StatusFound)
+}
@@ -1,33 +1,79 @@
 package redirect
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
 	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/lib/pages"
 	"github.com/Tbits007/url-shortener/internal/storage"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRedirectHandler(t *testing.T) {
 	cases := []struct {
-		name         string
-		alias        string
-		mockURL      string
-		mockError    error
-		expectedCode int
-		expectedURL  string
+		name          string
+		alias         string
+		mockURL       string
+		redirectType  int
+		forwardPath   bool
+		path          string
+		utm           storage.UTM
+		utmTemplate   string
+		templateUTM   storage.UTM
+		maxClicks     int
+		consumeError  error
+		mockError     error
+		expectedCode  int
+		expectedURL   string
+		expectedCache string
 	}{
 		{
-			name:         "successful redirect",
-			alias:        "test_alias",
-			mockURL:      "https://chat.deepseek.com/",
-			expectedCode: http.StatusFound,
-			expectedURL:  "https://chat.deepseek.com/",
+			name:          "successful redirect",
+			alias:         "test_alias",
+			mockURL:       "https://chat.deepseek.com/",
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://chat.deepseek.com/",
+			expectedCache: "private, max-age=0",
+		},
+		{
+			name:          "permanent redirect",
+			alias:         "vanity",
+			mockURL:       "https://example.com/landing",
+			redirectType:  http.StatusMovedPermanently,
+			expectedCode:  http.StatusMovedPermanently,
+			expectedURL:   "https://example.com/landing",
+			expectedCache: "public, max-age=3600",
+		},
+		{
+			name:          "method preserving redirect",
+			alias:         "api",
+			mockURL:       "https://api.example.com/v1",
+			redirectType:  http.StatusPermanentRedirect,
+			expectedCode:  http.StatusPermanentRedirect,
+			expectedURL:   "https://api.example.com/v1",
+			expectedCache: "public, max-age=3600",
+		},
+		{
+			name:          "temporary method preserving redirect",
+			alias:         "tmp",
+			mockURL:       "https://api.example.com/v2",
+			redirectType:  http.StatusTemporaryRedirect,
+			expectedCode:  http.StatusTemporaryRedirect,
+			expectedURL:   "https://api.example.com/v2",
+			expectedCache: "private, max-age=0",
 		},
 		{
 			name:         "empty alias",
@@ -46,35 +92,206 @@ func TestRedirectHandler(t *testing.T) {
 			mockError:    errors.New("database error"),
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			name:         "client canceled",
+			alias:        "test_canceled",
+			mockError:    fmt.Errorf("storage: %w", context.Canceled),
+			expectedCode: 499,
+		},
+		{
+			name:         "storage timeout",
+			alias:        "test_timeout",
+			mockError:    fmt.Errorf("storage: %w", context.DeadlineExceeded),
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:          "path passthrough",
+			alias:         "docs",
+			mockURL:       "https://example.com/docs",
+			forwardPath:   true,
+			path:          "/guide/install.pdf",
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/docs/guide/install.pdf",
+			expectedCache: "private, max-age=0",
+		},
+		{
+			name:          "utm template merged with link fields",
+			alias:         "promo",
+			mockURL:       "https://example.com/",
+			utm:           storage.UTM{Campaign: "launch"},
+			utmTemplate:   "newsletter",
+			templateUTM:   storage.UTM{Source: "newsletter", Medium: "email", Campaign: "default"},
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/?utm_campaign=launch&utm_medium=email&utm_source=newsletter",
+			expectedCache: "private, max-age=0",
+		},
+		{
+			name:          "limited link with clicks left",
+			alias:         "download",
+			mockURL:       "https://example.com/file.zip",
+			maxClicks:     1,
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/file.zip",
+			expectedCache: "private, no-store",
+		},
+		{
+			name:         "limited link exhausted",
+			alias:        "download",
+			mockURL:      "https://example.com/file.zip",
+			maxClicks:    1,
+			consumeError: storage.ErrClicksExhausted,
+			expectedCode: http.StatusGone,
+		},
+		{
+			name:         "path traversal",
+			alias:        "docs",
+			mockURL:      "https://example.com/docs",
+			forwardPath:  true,
+			path:         "/../admin",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "path passthrough disabled",
+			alias:        "docs",
+			mockURL:      "https://example.com/docs",
+			path:         "/guide",
+			expectedCode: http.StatusNotFound,
+		},
 	}
 
-    mockLog := slogdiscard.NewDiscardLogger()
+	mockLog := slogdiscard.NewDiscardLogger()
 
-    for _, tc := range cases {
-        t.Run(tc.name, func(t *testing.T) {
-            mockURLGetter := NewMockURLGetter(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
 			if tc.alias != "" {
-				mockURLGetter.On("GetURL", tc.alias).Return(tc.mockURL, tc.mockError).Once()
+				link := storage.Link{
+					Alias:        tc.alias,
+					URL:          tc.mockURL,
+					RedirectType: tc.redirectType,
+					ForwardPath:  tc.forwardPath,
+					UTM:          tc.utm,
+					UTMTemplate:  tc.utmTemplate,
+					TemplateUTM:  tc.templateUTM,
+					MaxClicks:    tc.maxClicks,
+				}
+				mockURLGetter.On("GetURL", mock.Anything, tc.alias).Return(link, tc.mockError).Once()
 			}
+			if tc.maxClicks > 0 {
+				mockURLGetter.On("ConsumeClick", mock.Anything, tc.alias).Return(0, tc.consumeError).Once()
+			}
+
+			handler := New(mockLog, mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:        http.StatusFound,
+				PermanentCacheMaxAge: time.Hour,
+			}, time.Now)
+
+			target := fmt.Sprintf("/%s%s", tc.alias, tc.path)
+			req := httptest.NewRequest(http.MethodGet, target, nil)
 
-			handler := New(mockLog, mockURLGetter)
-			
-            target := fmt.Sprintf("/%s", tc.alias)
-            req := httptest.NewRequest(http.MethodGet, target, nil)
-            
 			r := chi.NewRouter()
-            r.Get("/{alias}", handler)
+			r.Use(middleware.URLFormat)
+			r.Get("/{alias}", handler)
+			r.Get("/{alias}/*", handler)
 
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
-			
+
 			assert.Equal(t, tc.expectedCode, w.Code)
 
-			if tc.expectedCode == http.StatusFound {
+			if tc.expectedURL != "" {
 				location := w.Header().Get("Location")
 				assert.Equal(t, tc.expectedURL, location)
+				assert.Equal(t, tc.expectedCache, w.Header().Get("Cache-Control"))
 			}
 
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestRedirectHandler_Methods(t *testing.T) {
+	hash, err := linkpassword.Hash("s3cret")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		method        string
+		redirectType  int
+		passwordHash  string
+		expectedCode  int
+		expectedAllow string
+	}{
+		{name: "307 keeps POST", method: http.MethodPost, redirectType: http.StatusTemporaryRedirect, expectedCode: http.StatusTemporaryRedirect},
+		{name: "308 keeps PUT", method: http.MethodPut, redirectType: http.StatusPermanentRedirect, expectedCode: http.StatusPermanentRedirect},
+		{name: "308 keeps PATCH", method: http.MethodPatch, redirectType: http.StatusPermanentRedirect, expectedCode: http.StatusPermanentRedirect},
+		{name: "307 keeps DELETE", method: http.MethodDelete, redirectType: http.StatusTemporaryRedirect, expectedCode: http.StatusTemporaryRedirect},
+		{name: "301 rejects POST", method: http.MethodPost, redirectType: http.StatusMovedPermanently, expectedCode: http.StatusMethodNotAllowed, expectedAllow: "GET"},
+		{name: "default 302 rejects DELETE", method: http.MethodDelete, expectedCode: http.StatusMethodNotAllowed, expectedAllow: "GET"},
+		{
+			name:          "protected 302 takes only the password form",
+			method:        http.MethodPut,
+			passwordHash:  hash,
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, POST",
+		},
+		{
+			name:         "protected 307 requires the password first",
+			method:       http.MethodPut,
+			redirectType: http.StatusTemporaryRedirect,
+			passwordHash: hash,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockURLGetter.On("GetURL", mock.Anything, "api").Return(storage.Link{
+				Alias:        "api",
+				URL:          "https://example.com/api",
+				RedirectType: tc.redirectType,
+				PasswordHash: tc.passwordHash,
+			}, nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+			}, time.Now)
+
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			r.Method(tc.method, "/{alias}", handler)
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, "/api", strings.NewReader(`{"id": 1}`)))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedAllow, w.Header().Get("Allow"))
+			if tc.expectedCode == tc.redirectType {
+				assert.Equal(t, "https://example.com/api", w.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func newTestGuard(t *testing.T) *linkpassword.Guard {
+	t.Helper()
+
+	guard, err := linkpassword.NewGuard(config.LinkPassword{
+		CookieSecret:  "test-secret",
+		CookieTTL:     time.Hour,
+		MaxAttempts:   3,
+		AttemptWindow: time.Minute,
+	})
+	require.NoError(t, err)
+
+	return guard
+}
+
+func newTestPages(t *testing.T) *pages.Pages {
+	t.Helper()
+
+	p, err := pages.New(config.Pages{})
+	require.NoError(t, err)
+
+	return p
+}
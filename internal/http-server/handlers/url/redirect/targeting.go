@@ -0,0 +1,56 @@
+package redirect
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Tbits007/url-shortener/internal/lib/useragent"
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// client - то, что известно о посетителе: по этим признакам выбирается
+// правило таргетинга, и они же записываются в событие перехода.
+type client struct {
+	useragent.Info
+	Country  string
+	Language string
+}
+
+// matchTarget возвращает первое правило, подходящее клиенту, в порядке
+// Priority. Правила с одинаковым приоритетом проверяются в порядке списка.
+func matchTarget(rules []storage.TargetRule, c client) (storage.TargetRule, bool) {
+	ordered := slices.Clone(rules)
+	slices.SortStableFunc(ordered, func(a, b storage.TargetRule) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+
+	for _, rule := range ordered {
+		if matchRule(rule, c) {
+			return rule, true
+		}
+	}
+
+	return storage.TargetRule{}, false
+}
+
+func matchRule(rule storage.TargetRule, c client) bool {
+	if len(rule.OS) > 0 && !slices.Contains(rule.OS, c.OS) {
+		return false
+	}
+	if len(rule.Device) > 0 && !slices.Contains(rule.Device, c.Device) {
+		return false
+	}
+	if rule.Bot != nil && *rule.Bot != c.Bot {
+		return false
+	}
+	if len(rule.Country) > 0 && !slices.Contains(rule.Country, c.Country) {
+		return false
+	}
+	if len(rule.Language) > 0 && !slices.ContainsFunc(rule.Language, func(lang string) bool {
+		return matchLanguage(lang, c.Language)
+	}) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,238 @@
+package redirect
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/lib/useragent"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatchTarget(t *testing.T) {
+	yes, no := true, false
+
+	rules := []storage.TargetRule{
+		{Priority: 2, OS: []string{useragent.OSiOS}, URL: "https://apps.apple.com/app"},
+		{Priority: 2, OS: []string{useragent.OSAndroid}, URL: "https://play.google.com/store/apps"},
+		{Priority: 1, Bot: &yes, URL: "https://example.com/landing"},
+		{Priority: 3, Device: []string{useragent.DeviceMobile, useragent.DeviceTablet}, Bot: &no, URL: "https://m.example.com/"},
+	}
+
+	cases := []struct {
+		name    string
+		client  useragent.Info
+		wantURL string
+		wantOK  bool
+	}{
+		{
+			name:    "ios phone",
+			client:  useragent.Info{OS: useragent.OSiOS, Device: useragent.DeviceMobile},
+			wantURL: "https://apps.apple.com/app",
+			wantOK:  true,
+		},
+		{
+			name:    "android tablet",
+			client:  useragent.Info{OS: useragent.OSAndroid, Device: useragent.DeviceTablet},
+			wantURL: "https://play.google.com/store/apps",
+			wantOK:  true,
+		},
+		{
+			name:    "bot wins by priority",
+			client:  useragent.Info{OS: useragent.OSAndroid, Device: useragent.DeviceMobile, Bot: true},
+			wantURL: "https://example.com/landing",
+			wantOK:  true,
+		},
+		{
+			name:    "other mobile os",
+			client:  useragent.Info{Device: useragent.DeviceMobile},
+			wantURL: "https://m.example.com/",
+			wantOK:  true,
+		},
+		{
+			name:   "desktop falls through to default",
+			client: useragent.Info{OS: useragent.OSWindows, Device: useragent.DeviceDesktop},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, ok := matchTarget(rules, client{Info: tc.client})
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantURL, rule.URL)
+		})
+	}
+}
+
+func TestRedirectHandler_Targeting(t *testing.T) {
+	link := storage.Link{
+		Alias:        "app",
+		URL:          "https://example.com/",
+		RedirectType: http.StatusMovedPermanently,
+		Targeting: []storage.TargetRule{
+			{OS: []string{useragent.OSiOS}, URL: "https://apps.apple.com/app/id1"},
+			{OS: []string{useragent.OSAndroid}, URL: "https://play.google.com/store/apps/details?id=app"},
+		},
+	}
+
+	cases := []struct {
+		name        string
+		userAgent   string
+		expectedURL string
+	}{
+		{
+			name:        "ios",
+			userAgent:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148",
+			expectedURL: "https://apps.apple.com/app/id1",
+		},
+		{
+			name:        "android",
+			userAgent:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			expectedURL: "https://play.google.com/store/apps/details?id=app",
+		},
+		{
+			name:        "desktop gets default",
+			userAgent:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expectedURL: "https://example.com/",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockURLGetter.On("GetURL", mock.Anything, "app").Return(link, nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:        http.StatusFound,
+				PermanentCacheMaxAge: time.Hour,
+			}, time.Now)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/app", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusMovedPermanently, w.Code)
+			assert.Equal(t, tc.expectedURL, w.Header().Get("Location"))
+			assert.Equal(t, "User-Agent, Accept-Language", w.Header().Get("Vary"))
+			assert.Equal(t, "private, max-age=3600", w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestRedirectHandler_GeoAndLanguage(t *testing.T) {
+	link := storage.Link{
+		Alias: "shop",
+		URL:   "https://example.com/",
+		Targeting: []storage.TargetRule{
+			{Priority: 1, Country: []string{"DE", "AT"}, URL: "https://example.de/"},
+			{Priority: 2, Language: []string{"fr"}, URL: "https://example.fr/"},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		country        string
+		acceptLanguage string
+		expectedURL    string
+	}{
+		{
+			name:           "country rule",
+			country:        "AT",
+			acceptLanguage: "fr-FR,fr;q=0.9",
+			expectedURL:    "https://example.de/",
+		},
+		{
+			name:           "language rule",
+			country:        "CA",
+			acceptLanguage: "en;q=0.5, fr-CA",
+			expectedURL:    "https://example.fr/",
+		},
+		{
+			name:           "no match",
+			country:        "US",
+			acceptLanguage: "en-US,en;q=0.9",
+			expectedURL:    "https://example.com/",
+		},
+		{
+			name:        "unknown country and language",
+			expectedURL: "https://example.com/",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, "shop").Return(link, nil).Once()
+			mockURLGetter.On("RecordClick", mock.Anything, mock.MatchedBy(func(click storage.Click) bool {
+				// Событие перехода хранит и выбранный адрес, и признаки посетителя
+				return click.Alias == "shop" && click.At.Equal(now) &&
+					click.URL == tc.expectedURL && click.Country == tc.country &&
+					click.Language == preferredLanguage(tc.acceptLanguage)
+			})).Return(nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(tc.country), newTestPages(t), config.Redirect{
+				DefaultStatus: http.StatusFound,
+			}, func() time.Time { return now })
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/shop", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusFound, w.Code)
+			assert.Equal(t, tc.expectedURL, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_RecordClickFailure(t *testing.T) {
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "docs").Return(storage.Link{
+		Alias: "docs",
+		URL:   "https://example.com/docs",
+	}, nil).Once()
+	mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(errors.New("database error")).Once()
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	// Статистика потеряна, но посетитель все равно уходит по ссылке
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://example.com/docs", w.Header().Get("Location"))
+}
+
+// fixedCountry - CountryResolver, который считает всех посетителей
+// пришедшими из одной страны.
+type fixedCountry string
+
+func (c fixedCountry) Country(*http.Request) string {
+	return string(c)
+}
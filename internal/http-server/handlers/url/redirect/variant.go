@@ -0,0 +1,83 @@
+package redirect
+
+import (
+	"encoding/base64"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+const variantCookiePrefix = "link_variant_"
+
+// pickVariant выбирает вариант A/B-теста для посетителя. Выбор липкий:
+// вариант из куки сохраняется, пока он есть у ссылки, а без куки вариант
+// определяется хэшем адреса и User-Agent, поэтому клиент без кук тоже
+// не прыгает между вариантами. Возвращает false, если вариантов нет.
+func pickVariant(r *http.Request, link storage.Link) (storage.Variant, bool) {
+	if len(link.Variants) == 0 {
+		return storage.Variant{}, false
+	}
+
+	if c, err := r.Cookie(variantCookieName(link.Alias)); err == nil {
+		for _, v := range link.Variants {
+			if encodeCookie(v.Name) == c.Value {
+				return v, true
+			}
+		}
+	}
+
+	total := 0
+	for _, v := range link.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return link.Variants[0], true
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(link.Alias + "\x00" + host + "\x00" + r.UserAgent()))
+
+	bucket := int(h.Sum32() % uint32(total))
+	for _, v := range link.Variants {
+		if bucket < v.Weight {
+			return v, true
+		}
+		bucket -= v.Weight
+	}
+
+	return link.Variants[len(link.Variants)-1], true
+}
+
+// rememberVariant закрепляет вариант за посетителем. Кука на весь сайт,
+// как и кука пароля: путь /{alias} не покрыл бы предпросмотр /{alias}+.
+func rememberVariant(w http.ResponseWriter, r *http.Request, alias, name string, ttl time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     variantCookieName(alias),
+		Value:    encodeCookie(name),
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// variantCookieName кодирует алиас в имени куки: буквы не из ASCII
+// и пробелы в имени недопустимы, такую куку браузер бы не получил.
+func variantCookieName(alias string) string {
+	return variantCookiePrefix + encodeCookie(alias)
+}
+
+// encodeCookie переводит строку в символы, допустимые и в имени,
+// и в значении куки. Имя варианта так же произвольно, как алиас.
+func encodeCookie(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
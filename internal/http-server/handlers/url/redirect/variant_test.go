@@ -0,0 +1,190 @@
+package redirect
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var abLink = storage.Link{
+	Alias: "landing",
+	URL:   "https://example.com/",
+	Variants: []storage.Variant{
+		{Name: "control", URL: "https://example.com/a", Weight: 80},
+		{Name: "new", URL: "https://example.com/b", Weight: 20},
+	},
+}
+
+func TestPickVariant(t *testing.T) {
+	newRequest := func(addr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/landing", nil)
+		req.RemoteAddr = addr
+		req.Header.Set("User-Agent", "test")
+		return req
+	}
+
+	t.Run("sticky without cookie", func(t *testing.T) {
+		first, ok := pickVariant(newRequest("203.0.113.7:1234"), abLink)
+		require.True(t, ok)
+
+		// Другой исходящий порт - тот же посетитель
+		again, _ := pickVariant(newRequest("203.0.113.7:5678"), abLink)
+		assert.Equal(t, first, again)
+	})
+
+	t.Run("cookie wins over hash", func(t *testing.T) {
+		for _, name := range []string{"control", "new"} {
+			req := newRequest("203.0.113.7:1234")
+			req.AddCookie(&http.Cookie{Name: variantCookieName("landing"), Value: encodeCookie(name)})
+
+			v, _ := pickVariant(req, abLink)
+			assert.Equal(t, name, v.Name)
+		}
+	})
+
+	t.Run("removed variant in cookie is ignored", func(t *testing.T) {
+		req := newRequest("203.0.113.7:1234")
+		req.AddCookie(&http.Cookie{Name: variantCookieName("landing"), Value: encodeCookie("deleted")})
+
+		v, ok := pickVariant(req, abLink)
+		assert.True(t, ok)
+		assert.Contains(t, []string{"control", "new"}, v.Name)
+	})
+
+	t.Run("split follows weights", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			v, _ := pickVariant(newRequest(fmt.Sprintf("10.%d.%d.%d:1", i>>16&255, i>>8&255, i&255)), abLink)
+			counts[v.Name]++
+		}
+
+		assert.InDelta(t, 8000, counts["control"], 300)
+		assert.InDelta(t, 2000, counts["new"], 300)
+	})
+
+	t.Run("no variants", func(t *testing.T) {
+		_, ok := pickVariant(newRequest("203.0.113.7:1234"), storage.Link{Alias: "plain"})
+		assert.False(t, ok)
+	})
+}
+
+func TestRedirectHandler_Variants(t *testing.T) {
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "landing").Return(abLink, nil).Once()
+	mockURLGetter.On("RecordClick", mock.Anything, mock.MatchedBy(func(click storage.Click) bool {
+		return click.Variant == "new" && click.URL == "https://example.com/b"
+	})).Return(nil).Once()
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+		DefaultStatus:    http.StatusFound,
+		VariantCookieTTL: 24 * time.Hour,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/landing", nil)
+	req.AddCookie(&http.Cookie{Name: variantCookieName("landing"), Value: encodeCookie("new")})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://example.com/b", w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, variantCookieName("landing"), cookies[0].Name)
+	assert.Equal(t, encodeCookie("new"), cookies[0].Value)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.Equal(t, 86400, cookies[0].MaxAge)
+}
+
+func TestRedirectHandler_TargetingBeforeVariants(t *testing.T) {
+	link := abLink
+	link.Targeting = []storage.TargetRule{{Country: []string{"DE"}, URL: "https://example.de/"}}
+
+	mockURLGetter := NewMockURLGetter(t)
+	mockURLGetter.On("GetURL", mock.Anything, "landing").Return(link, nil).Once()
+	mockURLGetter.On("RecordClick", mock.Anything, mock.MatchedBy(func(click storage.Click) bool {
+		return click.Variant == "" && click.URL == "https://example.de/"
+	})).Return(nil).Once()
+
+	handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry("DE"), newTestPages(t), config.Redirect{
+		DefaultStatus: http.StatusFound,
+	}, time.Now)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/landing", nil))
+
+	assert.Equal(t, "https://example.de/", w.Header().Get("Location"))
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func TestRedirectHandler_VariantCookie(t *testing.T) {
+	cases := []struct {
+		name    string
+		alias   string
+		variant string
+	}{
+		{name: "ascii", alias: "landing", variant: "new"},
+		{name: "unicode alias", alias: "акция", variant: "new"},
+		{name: "unicode variant with spaces", alias: "landing", variant: "новый дизайн"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := storage.Link{
+				Alias: tc.alias,
+				URL:   "https://example.com/",
+				Variants: []storage.Variant{
+					{Name: "control", URL: "https://example.com/a", Weight: 1},
+					{Name: tc.variant, URL: "https://example.com/b", Weight: 1},
+				},
+			}
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("GetURL", mock.Anything, tc.alias).Return(link, nil)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil)
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:    http.StatusFound,
+				VariantCookieTTL: time.Hour,
+			}, time.Now)
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			// Посетитель с другого адреса без куки мог бы получить
+			// другой вариант: выбор держится только на куке
+			for i := range 10 {
+				req := httptest.NewRequest(http.MethodGet, "/"+url.PathEscape(tc.alias), nil)
+				req.RemoteAddr = fmt.Sprintf("198.51.100.%d:1", i)
+				req.AddCookie(&http.Cookie{Name: variantCookieName(tc.alias), Value: encodeCookie(tc.variant)})
+
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				require.Equal(t, "https://example.com/b", w.Header().Get("Location"))
+
+				cookies := w.Result().Cookies()
+				require.Len(t, cookies, 1)
+				require.NoError(t, cookies[0].Valid())
+				assert.Equal(t, encodeCookie(tc.variant), cookies[0].Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package redirect
+
+import (
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+type windowState int
+
+const (
+	windowActive windowState = iota
+	windowPending
+	windowExpired
+)
+
+// window определяет, где находится момент now относительно окна активности
+// ссылки. Начало окна включается, конец - нет.
+func window(link storage.Link, now time.Time) windowState {
+	switch {
+	case !link.ActiveFrom.IsZero() && now.Before(link.ActiveFrom):
+		return windowPending
+	case !link.ActiveUntil.IsZero() && !now.Before(link.ActiveUntil):
+		return windowExpired
+	default:
+		return windowActive
+	}
+}
@@ -0,0 +1,107 @@
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedirectHandler_ActivationWindow(t *testing.T) {
+	launch := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	end := launch.Add(24 * time.Hour)
+
+	cases := []struct {
+		name          string
+		now           time.Time
+		link          storage.Link
+		expectedCode  int
+		expectedURL   string
+		expectedCache string
+	}{
+		{
+			name:         "before window",
+			now:          launch.Add(-time.Second),
+			link:         storage.Link{ActiveFrom: launch},
+			expectedCode: http.StatusTooEarly,
+		},
+		{
+			name:          "before window with fallback",
+			now:           launch.Add(-time.Second),
+			link:          storage.Link{ActiveFrom: launch, PendingURL: "https://example.com/soon"},
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/soon",
+			expectedCache: "private, no-store",
+		},
+		{
+			name:          "window start is inclusive",
+			now:           launch,
+			link:          storage.Link{ActiveFrom: launch, ActiveUntil: end},
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/launch",
+			expectedCache: "private, max-age=0",
+		},
+		{
+			name:         "window end is exclusive",
+			now:          end,
+			link:         storage.Link{ActiveFrom: launch, ActiveUntil: end},
+			expectedCode: http.StatusGone,
+		},
+		{
+			name:          "after window with fallback",
+			now:           end.Add(time.Hour),
+			link:          storage.Link{ActiveUntil: end, ExpiredURL: "https://example.com/archive"},
+			expectedCode:  http.StatusFound,
+			expectedURL:   "https://example.com/archive",
+			expectedCache: "private, no-store",
+		},
+		{
+			name: "permanent redirect is not cached past window end",
+			now:  end.Add(-10 * time.Minute),
+			link: storage.Link{
+				ActiveUntil: end, RedirectType: http.StatusMovedPermanently,
+			},
+			expectedCode:  http.StatusMovedPermanently,
+			expectedURL:   "https://example.com/launch",
+			expectedCache: "public, max-age=600",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := tc.link
+			link.Alias = "launch"
+			link.URL = "https://example.com/launch"
+
+			mockURLGetter := NewMockURLGetter(t)
+			mockURLGetter.On("RecordClick", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockURLGetter.On("GetURL", mock.Anything, "launch").Return(link, nil).Once()
+
+			handler := New(slogdiscard.NewDiscardLogger(), mockURLGetter, newTestGuard(t), fixedCountry(""), newTestPages(t), config.Redirect{
+				DefaultStatus:          http.StatusFound,
+				PermanentCacheMaxAge:   time.Hour,
+				NotYetAvailableStatus:  http.StatusTooEarly,
+				NotYetAvailableMessage: "coming soon",
+			}, func() time.Time { return tc.now })
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/launch", nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedURL, w.Header().Get("Location"))
+			if tc.expectedCache != "" {
+				assert.Equal(t, tc.expectedCache, w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
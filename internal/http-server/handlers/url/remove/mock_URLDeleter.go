@@ -0,0 +1,83 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package remove
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockURLDeleter is an autogenerated mock type for the URLDeleter type
+type MockURLDeleter struct {
+	mock.Mock
+}
+
+type MockURLDeleter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockURLDeleter) EXPECT() *MockURLDeleter_Expecter {
+	return &MockURLDeleter_Expecter{mock: &_m.Mock}
+}
+
+// DeleteURL provides a mock function with given fields: ctx, alias
+func (_m *MockURLDeleter) DeleteURL(ctx context.Context, alias string) error {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockURLDeleter_DeleteURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteURL'
+type MockURLDeleter_DeleteURL_Call struct {
+	*mock.Call
+}
+
+// DeleteURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockURLDeleter_Expecter) DeleteURL(ctx interface{}, alias interface{}) *MockURLDeleter_DeleteURL_Call {
+	return &MockURLDeleter_DeleteURL_Call{Call: _e.mock.On("DeleteURL", ctx, alias)}
+}
+
+func (_c *MockURLDeleter_DeleteURL_Call) Run(run func(ctx context.Context, alias string)) *MockURLDeleter_DeleteURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockURLDeleter_DeleteURL_Call) Return(_a0 error) *MockURLDeleter_DeleteURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockURLDeleter_DeleteURL_Call) RunAndReturn(run func(context.Context, string) error) *MockURLDeleter_DeleteURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockURLDeleter creates a new instance of MockURLDeleter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockURLDeleter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockURLDeleter {
+	mock := &MockURLDeleter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+package remove
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type URLDeleter interface {
+	DeleteURL(ctx context.Context, alias string) error
+}
+
+// New удаляет ссылку и ее статистику. Алиас после этого снова свободен.
+func New(log *slog.Logger, deleter URLDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.remove.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		err := deleter.DeleteURL(r.Context(), alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			log.Info("request canceled by client", sl.Err(err))
+			render.Status(r, resp.StatusClientClosedRequest)
+			render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Error("storage timeout", sl.Err(err))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+
+			return
+		}
+
+		log.Info("url deleted", slog.String("alias", alias))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,68 @@
+package remove
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRemoveHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "success",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "url not found",
+			mockError:    storage.ErrURLNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":"Error","error":"not found","code":"not_found"}`,
+		},
+		{
+			name:         "storage timeout",
+			mockError:    fmt.Errorf("storage: %w", context.DeadlineExceeded),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"Error","error":"storage timeout","code":"storage_timeout"}`,
+		},
+		{
+			name:         "internal error",
+			mockError:    errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":"Error","error":"internal error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDeleter := NewMockURLDeleter(t)
+			mockDeleter.On("DeleteURL", mock.Anything, "docs").Return(tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Delete("/api/v1/links/{alias}", New(slogdiscard.NewDiscardLogger(), mockDeleter))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/links/docs", nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			} else {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
@@ -2,7 +2,12 @@
 
 package save
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockURLSaver is an autogenerated mock type for the URLSaver type
 type MockURLSaver struct {
@@ -17,17 +22,17 @@ func (_m *MockURLSaver) EXPECT() *MockURLSaver_Expecter {
 	return &MockURLSaver_Expecter{mock: &_m.Mock}
 }
 
-// SaveURL provides a mock function with given fields: urlToSave, alias
-func (_m *MockURLSaver) SaveURL(urlToSave string, alias string) error {
-	ret := _m.Called(urlToSave, alias)
+// SaveURL provides a mock function with given fields: ctx, link
+func (_m *MockURLSaver) SaveURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveURL")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(urlToSave, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -41,15 +46,15 @@ type MockURLSaver_SaveURL_Call struct {
 }
 
 // SaveURL is a helper method to define mock.On call
-//   - urlToSave string
-//   - alias string
-func (_e *MockURLSaver_Expecter) SaveURL(urlToSave interface{}, alias interface{}) *MockURLSaver_SaveURL_Call {
-	return &MockURLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", urlToSave, alias)}
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockURLSaver_Expecter) SaveURL(ctx interface{}, link interface{}) *MockURLSaver_SaveURL_Call {
+	return &MockURLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", ctx, link)}
 }
 
-func (_c *MockURLSaver_SaveURL_Call) Run(run func(urlToSave string, alias string)) *MockURLSaver_SaveURL_Call {
+func (_c *MockURLSaver_SaveURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockURLSaver_SaveURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(storage.Link))
 	})
 	return _c
 }
@@ -59,7 +64,7 @@ func (_c *MockURLSaver_SaveURL_Call) Return(_a0 error) *MockURLSaver_SaveURL_Cal
 	return _c
 }
 
-func (_c *MockURLSaver_SaveURL_Call) RunAndReturn(run func(string, string) error) *MockURLSaver_SaveURL_Call {
+func (_c *MockURLSaver_SaveURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockURLSaver_SaveURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
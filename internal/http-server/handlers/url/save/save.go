@@ -1,14 +1,22 @@
 package save
 
 import (
+	"context"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
 	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
 	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
 	"github.com/Tbits007/url-shortener/internal/lib/random"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
 	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	validator "github.com/go-playground/validator/v10"
@@ -16,18 +24,179 @@ import (
 
 const aliasLength = 6
 
-type Request struct {
-    URL   string `json:"url" validate:"required,url"`
-    Alias string `json:"alias,omitempty"`
+// Суффикс алиаса, по которому redirect показывает предпросмотр ссылки.
+const previewSuffix = "+"
+
+// Алиасы, совпадающие со служебными маршрутами сервера.
+// Такую ссылку невозможно было бы открыть, поэтому их нельзя занимать.
+var reservedAliases = map[string]struct{}{
+    "healthz": {},
+    "readyz":  {},
+    "metrics": {},
+    "saveURL": {},
+    "url":     {},
+
+    "utm-templates": {},
+    "openapi":       {},
+    "api":           {},
+}
+
+// Валидатор называет поля по тегам json, как их видит клиент.
+// Кэширует разобранные структуры, поэтому создается один раз.
+var validate = resp.NewValidator()
+
+// Request - тело запроса на создание ссылки. Поля описаны в pkg/api;
+// свой тип нужен для методов.
+type Request api.CreateRequest
+
+// LogValue не дает паролю попасть в логи.
+func (req Request) LogValue() slog.Value {
+    type plain Request
+
+    if req.Password != "" {
+        req.Password = "[REDACTED]"
+    }
+
+    return slog.AnyValue(plain(req))
+}
+
+// Link переносит настройки из запроса в ссылку. Пароль не переносится:
+// в ссылке хранится только его хэш.
+func (req Request) Link(alias string) storage.Link {
+    link := storage.Link{
+        Alias:         alias,
+        URL:           req.URL,
+        Title:         req.Title,
+        RedirectType:  req.RedirectType,
+        ForwardQuery:  req.ForwardQuery,
+        QueryConflict: req.QueryConflict,
+        ForwardPath:   req.ForwardPath,
+        UTM:           req.UTM,
+        UTMTemplate:   req.UTMTemplate,
+        MaxClicks:     req.MaxClicks,
+        PendingURL:    req.PendingURL,
+        ExpiredURL:    req.ExpiredURL,
+        Targeting:     req.Targeting,
+        Variants:      req.Variants,
+        Interstitial:  req.Interstitial,
+        OG:            req.OG,
+    }
+    if req.ActiveFrom != nil {
+        link.ActiveFrom = *req.ActiveFrom
+    }
+    if req.ActiveUntil != nil {
+        link.ActiveUntil = *req.ActiveUntil
+    }
+
+    return link
+}
+
+// NewLink собирает новую ссылку: генерирует алиас, если он не задан,
+// и хэширует пароль.
+func (req Request) NewLink(owner string) (storage.Link, error) {
+    alias := req.Alias
+    if alias == "" {
+        alias = random.NewRandomString(aliasLength)
+    }
+
+    link := req.Link(alias)
+    link.Owner = owner
+    if req.Password != "" {
+        hash, err := linkpassword.Hash(req.Password)
+        if err != nil {
+            return storage.Link{}, err
+        }
+        link.PasswordHash = hash
+    }
+
+    return link, nil
+}
+
+// IsReserved сообщает, совпадает ли алиас со служебным маршрутом.
+func IsReserved(alias string) bool {
+    _, ok := reservedAliases[alias]
+    return ok
 }
 
-type Response struct {
-    resp.Response
-    Alias string `json:"alias"`
+// RequestFromLink - обратное к Link преобразование. Вместе с Link позволяет
+// менять ссылку частично: поля запроса на изменение ложатся поверх текущих.
+func RequestFromLink(link storage.Link) Request {
+    req := Request{
+        URL:           link.URL,
+        Alias:         link.Alias,
+        Title:         link.Title,
+        RedirectType:  link.RedirectType,
+        ForwardQuery:  link.ForwardQuery,
+        QueryConflict: link.QueryConflict,
+        ForwardPath:   link.ForwardPath,
+        UTM:           link.UTM,
+        UTMTemplate:   link.UTMTemplate,
+        MaxClicks:     link.MaxClicks,
+        PendingURL:    link.PendingURL,
+        ExpiredURL:    link.ExpiredURL,
+        Targeting:     link.Targeting,
+        Variants:      link.Variants,
+        Interstitial:  link.Interstitial,
+        OG:            link.OG,
+    }
+    if !link.ActiveFrom.IsZero() {
+        req.ActiveFrom = &link.ActiveFrom
+    }
+    if !link.ActiveUntil.IsZero() {
+        req.ActiveUntil = &link.ActiveUntil
+    }
+
+    return req
 }
 
+// Validate проверяет запрос по правилам тегов validate и тем, что
+// тегами не выразить. Пустой результат - запрос корректен.
+func (req Request) Validate() []resp.FieldError {
+    if err := validate.Struct(req); err != nil {
+        return resp.FieldErrors(err.(validator.ValidationErrors))
+    }
+
+    if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveUntil.After(*req.ActiveFrom) {
+        return []resp.FieldError{{
+            Field:   "active_until",
+            Rule:    "gtfield",
+            Param:   "active_from",
+            Message: "active_until must be after active_from",
+        }}
+    }
+
+    if strings.HasSuffix(req.Alias, previewSuffix) {
+        // /{alias}+ открывает предпросмотр ссылки {alias}
+        return []resp.FieldError{{
+            Field:   "alias",
+            Rule:    "excludes_suffix",
+            Param:   previewSuffix,
+            Message: "alias must not end with " + previewSuffix,
+        }}
+    }
+
+    if strings.IndexFunc(req.Alias, notAliasRune) >= 0 {
+        // Алиас - один сегмент пути: "/" или "." сломали бы маршрутизацию,
+        // а "?", "#" и пробелы пришлось бы экранировать в каждой ссылке
+        return []resp.FieldError{{
+            Field:   "alias",
+            Rule:    "alphanumunicode",
+            Param:   "-_",
+            Message: "alias may contain only letters, digits, - and _",
+        }}
+    }
+
+    return nil
+}
+
+func notAliasRune(r rune) bool {
+    return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+}
+
+type Response = api.CreateResponse
+
 type URLSaver interface {
-    SaveURL(urlToSave, alias string) error
+    SaveURL(ctx context.Context, link storage.Link) error
 }
 
 func responseOK(w http.ResponseWriter, r *http.Request, alias string) {
@@ -37,17 +206,35 @@ func responseOK(w http.ResponseWriter, r *http.Request, alias string) {
     })
 }
 
-
+// New - обработчик /saveURL: на успех отвечает 200.
 func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
+    return newHandler(log, urlSaver, responseOK)
+}
+
+// NewCreated отвечает на успех 201 с адресом созданной ссылки
+// в Location: linksPath + "/" + alias.
+func NewCreated(log *slog.Logger, urlSaver URLSaver, linksPath string) http.HandlerFunc {
+    return newHandler(log, urlSaver, func(w http.ResponseWriter, r *http.Request, alias string) {
+        w.Header().Set("Location", linksPath+"/"+url.PathEscape(alias))
+        render.Status(r, http.StatusCreated)
+        responseOK(w, r, alias)
+    })
+}
+
+func newHandler(
+    log *slog.Logger,
+    urlSaver URLSaver,
+    respond func(w http.ResponseWriter, r *http.Request, alias string),
+) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         const op = "handlers.url.save.New"
 
         // Добавляем к текущему объекту логгера поля op и request_id
         // Они могут очень упростить нам жизнь в будущем
-        log = log.With(
+        log := log.With(
             slog.String("op", op),
             slog.String("request_id", middleware.GetReqID(r.Context())),
-        )
+        ).With(tracing.LogAttrs(r.Context())...)
 
         // Создаем объект запроса и анмаршаллим в него запрос
         var req Request
@@ -58,12 +245,14 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
             // Обработаем её отдельно
             log.Error("request body is empty")
 
-            render.JSON(w, r, resp.Error("empty request"))
+            w.WriteHeader(http.StatusBadRequest)
+            render.JSON(w, r, resp.Error(resp.CodeEmptyRequest, "empty request"))
             return
         }
         if err != nil {
             log.Error("failed to decode request body", sl.Err(err))
-            render.JSON(w, r, resp.Error("failed to decode request"))
+            w.WriteHeader(http.StatusBadRequest)
+            render.JSON(w, r, resp.Error(resp.CodeInvalidJSON, "failed to decode request"))
             return
         }
 
@@ -71,38 +260,66 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
         // при необходимости. А вот недостающую информацию мы уже не получим.
         log.Info("request body decoded", slog.Any("req", req))
 
-		// Создаем объект валидатора
-		// и передаем в него структуру, которую нужно провалидировать
-		if err := validator.New().Struct(req); err != nil {
-			// Приводим ошибку к типу ошибки валидации
-			validateErr := err.(validator.ValidationErrors)
-		
-			log.Error("invalid request", sl.Err(err))
-            
-			render.JSON(w, r, resp.ValidationError(validateErr))
-			return
-		}
-		
-		alias := req.Alias
-		if alias == "" {
-			alias = random.NewRandomString(aliasLength)
-		}	
-		
-        err = urlSaver.SaveURL(req.URL, alias)
+        if details := req.Validate(); len(details) > 0 {
+            log.Info("invalid request", slog.Any("details", details))
+            w.WriteHeader(http.StatusUnprocessableEntity)
+            render.JSON(w, r, resp.ValidationFailed(details...))
+            return
+        }
+
+        if IsReserved(req.Alias) {
+            log.Info("alias is reserved", slog.String("alias", req.Alias))
+            w.WriteHeader(http.StatusConflict)
+            render.JSON(w, r, resp.Error(resp.CodeAliasReserved, "alias is reserved"))
+            return
+        }
+
+        // Владелец - пользователь basic auth, под которым создана ссылка
+        owner, _, _ := r.BasicAuth()
+        link, err := req.NewLink(owner)
+        if err != nil {
+            log.Error("failed to hash password", sl.Err(err))
+            w.WriteHeader(http.StatusInternalServerError)
+            render.JSON(w, r, resp.Error(resp.CodeInternal, "failed to add url"))
+            return
+        }
+        alias := link.Alias
+
+        err = urlSaver.SaveURL(r.Context(), link)
         if errors.Is(err, storage.ErrURLExists) {
             log.Info("url already exists", slog.String("url", req.URL))
-            w.WriteHeader(http.StatusBadRequest)
-            render.JSON(w, r, resp.Error("url already exists"))
+            w.WriteHeader(http.StatusConflict)
+            render.JSON(w, r, resp.Error(resp.CodeAliasExists, "url already exists"))
+            return
+        }
+        if errors.Is(err, storage.ErrUTMTemplateNotFound) {
+            log.Info("utm template not found", slog.String("template", req.UTMTemplate))
+            w.WriteHeader(http.StatusUnprocessableEntity)
+            render.JSON(w, r, resp.Error(resp.CodeUTMTemplateNotFound, "utm template not found"))
+            return
+        }
+        if errors.Is(err, context.Canceled) {
+            log.Info("request canceled by client", sl.Err(err))
+            render.Status(r, resp.StatusClientClosedRequest)
+            render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+            return
+        }
+        if errors.Is(err, context.DeadlineExceeded) {
+            log.Error("storage timeout", sl.Err(err))
+            render.Status(r, http.StatusServiceUnavailable)
+            render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
             return
         }
         if err != nil {
             log.Error("failed to add url", sl.Err(err))
             w.WriteHeader(http.StatusInternalServerError)
-            render.JSON(w, r, resp.Error("failed to add url"))
+            render.JSON(w, r, resp.Error(resp.CodeInternal, "failed to add url"))
             return
         }
 
-        responseOK(w, r, alias)
-    }	
-		 
+        metrics.LinksCreatedTotal.Inc()
+
+        respond(w, r, alias)
+    }
+
 }
\ No newline at end of file
@@ -2,15 +2,20 @@ package save
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
 	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
 	"github.com/Tbits007/url-shortener/internal/storage"
 	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 
@@ -20,7 +25,9 @@ func TestSaveHandler(t *testing.T) {
         url          string
         alias        string
         expectedCode int
+        redirectType int
         mockError    error 
+        skipSave     bool
     }{
         {
             name:         "success: with alias",
@@ -34,13 +41,90 @@ func TestSaveHandler(t *testing.T) {
             alias:        "",
             expectedCode: http.StatusOK,
         },
+        {
+            name:         "success: permanent redirect",
+            url:          "https://github.com/",
+            alias:        "vanity",
+            redirectType: http.StatusMovedPermanently,
+            expectedCode: http.StatusOK,
+        },
+        {
+            name:         "error: invalid redirect type",
+            url:          "https://github.com/",
+            alias:        "bad_redirect",
+            redirectType: http.StatusOK,
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
         {
             name:         "error: url exists",
             url:          "https://exists.com/",
             alias:        "exists",
-            expectedCode: http.StatusBadRequest,
+            expectedCode: http.StatusConflict,
             mockError:   storage.ErrURLExists,
         },
+        {
+            name:         "error: reserved alias",
+            url:          "https://github.com/",
+            alias:        "healthz",
+            expectedCode: http.StatusConflict,
+            skipSave:     true,
+        },
+        {
+            name:         "error: alias with slash",
+            url:          "https://github.com/",
+            alias:        "docs/v2",
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+        {
+            name:         "error: alias with dot",
+            url:          "https://github.com/",
+            alias:        "docs.json",
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+        {
+            name:         "error: alias with query",
+            url:          "https://github.com/",
+            alias:        "docs?x=1",
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+        {
+            name:         "error: alias with space",
+            url:          "https://github.com/",
+            alias:        "my docs",
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+        {
+            name:         "error: alias with percent",
+            url:          "https://github.com/",
+            alias:        "docs%2F",
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+        {
+            name:         "success: unicode alias with - and _",
+            url:          "https://github.com/",
+            alias:        "ссылка-2_0",
+            expectedCode: http.StatusOK,
+        },
+        {
+            name:         "error: client canceled",
+            url:          "https://github.com/",
+            alias:        "canceled",
+            expectedCode: 499,
+            mockError:    fmt.Errorf("storage: %w", context.Canceled),
+        },
+        {
+            name:         "error: storage timeout",
+            url:          "https://github.com/",
+            alias:        "timeout",
+            expectedCode: http.StatusServiceUnavailable,
+            mockError:    fmt.Errorf("storage: %w", context.DeadlineExceeded),
+        },
     }
 
     mockLog := slogdiscard.NewDiscardLogger()
@@ -49,21 +133,29 @@ func TestSaveHandler(t *testing.T) {
         t.Run(tc.name, func(t *testing.T) {
             mockURLsaver := NewMockURLSaver(t)
             
-            if tc.alias == "" {
+            if tc.skipSave {
+                // Запрос должен быть отклонен до обращения к хранилищу
+            } else if tc.alias == "" {
                 // Для случая с генерацией алиаса
-                mockURLsaver.On("SaveURL", tc.url, mock.AnythingOfType("string")).
+                mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+                    return link.URL == tc.url && len(link.Alias) == aliasLength
+                })).
                     Return(tc.mockError).
                     Once()
             } else {
                 // Для случая с указанным алиасом
-                mockURLsaver.On("SaveURL", tc.url, tc.alias).
+                mockURLsaver.On("SaveURL", mock.Anything, storage.Link{
+                    Alias:        tc.alias,
+                    URL:          tc.url,
+                    RedirectType: tc.redirectType,
+                }).
                     Return(tc.mockError).
                     Once()
             }
 
             handler := New(mockLog, mockURLsaver)
 
-            body := fmt.Sprintf(`{"url": "%s", "alias": "%s"}`, tc.url, tc.alias)
+            body := fmt.Sprintf(`{"url": "%s", "alias": "%s", "redirect_type": %d}`, tc.url, tc.alias, tc.redirectType)
             req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(body)))
             w := httptest.NewRecorder()
 
@@ -72,4 +164,284 @@ func TestSaveHandler(t *testing.T) {
             assert.Equal(t, tc.expectedCode, w.Code)
         })
     }
-}
\ No newline at end of file
+}
+func TestSaveHandler_Password(t *testing.T) {
+    mockURLsaver := NewMockURLSaver(t)
+    mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+        // В хранилище должен попасть хэш, а не сам пароль
+        return link.PasswordHash != "" && link.PasswordHash != "s3cret" &&
+            bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte("s3cret")) == nil
+    })).Return(nil).Once()
+
+    handler := New(slogdiscard.NewDiscardLogger(), mockURLsaver)
+
+    body := `{"url": "https://github.com/", "alias": "private", "password": "s3cret"}`
+    req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(body)))
+    w := httptest.NewRecorder()
+
+    handler(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSaveHandler_ActivationWindow(t *testing.T) {
+    cases := []struct {
+        name         string
+        body         string
+        expectedCode int
+        skipSave     bool
+    }{
+        {
+            name:         "success: window with fallbacks",
+            body:         `{"url": "https://example.com/sale", "alias": "sale", "active_from": "2025-11-28T00:00:00Z", "active_until": "2025-12-01T00:00:00Z", "pending_url": "https://example.com/soon", "expired_url": "https://example.com/"}`,
+            expectedCode: http.StatusOK,
+        },
+        {
+            name:         "error: window ends before it starts",
+            body:         `{"url": "https://example.com/sale", "alias": "sale", "active_from": "2025-12-01T00:00:00Z", "active_until": "2025-11-28T00:00:00Z"}`,
+            expectedCode: http.StatusUnprocessableEntity,
+            skipSave:     true,
+        },
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            mockURLsaver := NewMockURLSaver(t)
+            if !tc.skipSave {
+                mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+                    return !link.ActiveFrom.IsZero() && link.ActiveUntil.After(link.ActiveFrom) &&
+                        link.PendingURL == "https://example.com/soon"
+                })).Return(nil).Once()
+            }
+
+            handler := New(slogdiscard.NewDiscardLogger(), mockURLsaver)
+
+            req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(tc.body)))
+            w := httptest.NewRecorder()
+
+            handler(w, req)
+
+            assert.Equal(t, tc.expectedCode, w.Code)
+        })
+    }
+}
+
+func TestSaveHandler_Targeting(t *testing.T) {
+    cases := []struct {
+        name           string
+        body           string
+        expectedStatus string
+        skipSave       bool
+    }{
+        {
+            name:           "success: platform and geo rules",
+            body:           `{"url": "https://example.com/", "alias": "app", "targeting": [{"priority": 1, "os": ["ios"], "url": "https://apps.apple.com/app/id1"}, {"priority": 2, "country": ["DE", "AT"], "language": ["de", "pt-BR"], "url": "https://example.de/"}]}`,
+            expectedStatus: resp.StatusOK,
+        },
+        {
+            name:           "error: unknown os",
+            body:           `{"url": "https://example.com/", "alias": "app", "targeting": [{"os": ["symbian"], "url": "https://example.com/old"}]}`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+        {
+            name:           "error: country is not an iso code",
+            body:           `{"url": "https://example.com/", "alias": "app", "targeting": [{"country": ["Germany"], "url": "https://example.de/"}]}`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+        {
+            name:           "error: invalid language tag",
+            body:           `{"url": "https://example.com/", "alias": "app", "targeting": [{"language": ["not a language"], "url": "https://example.de/"}]}`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+        {
+            name:           "error: rule without url",
+            body:           `{"url": "https://example.com/", "alias": "app", "targeting": [{"os": ["ios"]}]}`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            mockURLsaver := NewMockURLSaver(t)
+            if !tc.skipSave {
+                mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+                    return len(link.Targeting) == 2 && link.Targeting[0].URL == "https://apps.apple.com/app/id1"
+                })).Return(nil).Once()
+            }
+
+            handler := New(slogdiscard.NewDiscardLogger(), mockURLsaver)
+
+            req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(tc.body)))
+            w := httptest.NewRecorder()
+
+            handler(w, req)
+
+            var body resp.Response
+            require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+            assert.Equal(t, tc.expectedStatus, body.Status)
+        })
+    }
+}
+
+func TestSaveHandler_Variants(t *testing.T) {
+    cases := []struct {
+        name           string
+        variants       string
+        expectedStatus string
+        skipSave       bool
+    }{
+        {
+            name:           "success: weighted split",
+            variants:       `[{"name": "a", "url": "https://example.com/a", "weight": 70}, {"name": "b", "url": "https://example.com/b", "weight": 30}]`,
+            expectedStatus: resp.StatusOK,
+        },
+        {
+            name:           "error: single variant",
+            variants:       `[{"name": "a", "url": "https://example.com/a", "weight": 1}]`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+        {
+            name:           "error: duplicate names",
+            variants:       `[{"name": "a", "url": "https://example.com/a", "weight": 1}, {"name": "a", "url": "https://example.com/b", "weight": 1}]`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+        {
+            name:           "error: zero weight",
+            variants:       `[{"name": "a", "url": "https://example.com/a", "weight": 1}, {"name": "b", "url": "https://example.com/b", "weight": 0}]`,
+            expectedStatus: resp.StatusError,
+            skipSave:       true,
+        },
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            mockURLsaver := NewMockURLSaver(t)
+            if !tc.skipSave {
+                mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+                    return len(link.Variants) == 2 && link.Variants[0].Weight == 70
+                })).Return(nil).Once()
+            }
+
+            handler := New(slogdiscard.NewDiscardLogger(), mockURLsaver)
+
+            body := `{"url": "https://example.com/", "alias": "landing", "variants": ` + tc.variants + `}`
+            req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(body)))
+            w := httptest.NewRecorder()
+
+            handler(w, req)
+
+            var res resp.Response
+            require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+            assert.Equal(t, tc.expectedStatus, res.Status)
+        })
+    }
+}
+
+func TestSaveHandler_OwnerAndPreviewSuffix(t *testing.T) {
+    t.Run("owner from basic auth", func(t *testing.T) {
+        mockURLsaver := NewMockURLSaver(t)
+        mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+            return link.Owner == "admin" && link.Title == "Docs" && link.Interstitial
+        })).Return(nil).Once()
+
+        handler := New(slogdiscard.NewDiscardLogger(), mockURLsaver)
+
+        body := `{"url": "https://example.com/", "alias": "docs", "title": "Docs", "interstitial": true}`
+        req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(body)))
+        req.SetBasicAuth("admin", "secret")
+        w := httptest.NewRecorder()
+
+        handler(w, req)
+
+        assert.Equal(t, http.StatusOK, w.Code)
+    })
+
+    t.Run("alias with preview suffix", func(t *testing.T) {
+        handler := New(slogdiscard.NewDiscardLogger(), NewMockURLSaver(t))
+
+        body := `{"url": "https://example.com/", "alias": "docs+"}`
+        req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(body)))
+        w := httptest.NewRecorder()
+
+        handler(w, req)
+
+        assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+    })
+}
+
+func TestSaveHandler_ErrorCodes(t *testing.T) {
+    cases := []struct {
+        name            string
+        body            string
+        expectedCode    int
+        expectedErrCode string
+        expectedDetails []resp.FieldError
+    }{
+        {
+            name:            "empty body",
+            body:            ``,
+            expectedCode:    http.StatusBadRequest,
+            expectedErrCode: resp.CodeEmptyRequest,
+        },
+        {
+            name:            "malformed json",
+            body:            `{"url": `,
+            expectedCode:    http.StatusBadRequest,
+            expectedErrCode: resp.CodeInvalidJSON,
+        },
+        {
+            name:            "field errors use json paths",
+            body:            `{"url": "not a url", "targeting": [{"os": ["ios"], "url": "https://example.com/ios"}, {"os": ["ios"]}]}`,
+            expectedCode:    http.StatusUnprocessableEntity,
+            expectedErrCode: resp.CodeValidationFailed,
+            expectedDetails: []resp.FieldError{
+                {Field: "url", Rule: "url", Message: "field url is not a valid URL"},
+                {Field: "targeting[1].url", Rule: "required", Message: "field targeting[1].url is a required field"},
+            },
+        },
+    }
+
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            handler := New(slogdiscard.NewDiscardLogger(), NewMockURLSaver(t))
+
+            req := httptest.NewRequest(http.MethodPost, "/saveURL", bytes.NewReader([]byte(tc.body)))
+            w := httptest.NewRecorder()
+
+            handler(w, req)
+
+            assert.Equal(t, tc.expectedCode, w.Code)
+
+            var res resp.Response
+            require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+            assert.Equal(t, resp.StatusError, res.Status)
+            assert.Equal(t, tc.expectedErrCode, res.Code)
+            assert.Equal(t, tc.expectedDetails, res.Details)
+        })
+    }
+}
+
+func TestSaveHandler_Created(t *testing.T) {
+    mockURLsaver := NewMockURLSaver(t)
+    mockURLsaver.On("SaveURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+        return link.Alias == "docs"
+    })).Return(nil).Once()
+
+    handler := NewCreated(slogdiscard.NewDiscardLogger(), mockURLsaver, "/api/v1/links")
+
+    body := `{"url": "https://example.com/", "alias": "docs"}`
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewReader([]byte(body)))
+    w := httptest.NewRecorder()
+
+    handler(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "/api/v1/links/docs", w.Header().Get("Location"))
+    assert.JSONEq(t, `{"status":"OK","alias":"docs"}`, w.Body.String())
+}
@@ -0,0 +1,151 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package stats
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStatsGetter is an autogenerated mock type for the StatsGetter type
+type MockStatsGetter struct {
+	mock.Mock
+}
+
+type MockStatsGetter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStatsGetter) EXPECT() *MockStatsGetter_Expecter {
+	return &MockStatsGetter_Expecter{mock: &_m.Mock}
+}
+
+// GetClickStats provides a mock function with given fields: ctx, alias
+func (_m *MockStatsGetter) GetClickStats(ctx context.Context, alias string) (storage.ClickStats, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClickStats")
+	}
+
+	var r0 storage.ClickStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.ClickStats, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.ClickStats); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.ClickStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStatsGetter_GetClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClickStats'
+type MockStatsGetter_GetClickStats_Call struct {
+	*mock.Call
+}
+
+// GetClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStatsGetter_Expecter) GetClickStats(ctx interface{}, alias interface{}) *MockStatsGetter_GetClickStats_Call {
+	return &MockStatsGetter_GetClickStats_Call{Call: _e.mock.On("GetClickStats", ctx, alias)}
+}
+
+func (_c *MockStatsGetter_GetClickStats_Call) Run(run func(ctx context.Context, alias string)) *MockStatsGetter_GetClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStatsGetter_GetClickStats_Call) Return(_a0 storage.ClickStats, _a1 error) *MockStatsGetter_GetClickStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStatsGetter_GetClickStats_Call) RunAndReturn(run func(context.Context, string) (storage.ClickStats, error)) *MockStatsGetter_GetClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockStatsGetter) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURL")
+	}
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStatsGetter_GetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURL'
+type MockStatsGetter_GetURL_Call struct {
+	*mock.Call
+}
+
+// GetURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStatsGetter_Expecter) GetURL(ctx interface{}, alias interface{}) *MockStatsGetter_GetURL_Call {
+	return &MockStatsGetter_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
+}
+
+func (_c *MockStatsGetter_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockStatsGetter_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStatsGetter_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockStatsGetter_GetURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStatsGetter_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockStatsGetter_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStatsGetter creates a new instance of MockStatsGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStatsGetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStatsGetter {
+	mock := &MockStatsGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
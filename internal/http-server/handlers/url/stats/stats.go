@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type (
+	Response     = api.Stats
+	VariantStats = api.VariantStats
+)
+
+// VariantStats - переходы по одному варианту A/B-теста.
+type StatsGetter interface {
+	GetURL(ctx context.Context, alias string) (storage.Link, error)
+	GetClickStats(ctx context.Context, alias string) (storage.ClickStats, error)
+}
+
+func New(log *slog.Logger, statsGetter StatsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.stats.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		// Ссылка нужна, чтобы показать и варианты, по которым еще не было переходов
+		link, err := statsGetter.GetURL(r.Context(), alias)
+		if err != nil {
+			renderError(log, w, r, err)
+
+			return
+		}
+
+		clicks, err := statsGetter.GetClickStats(r.Context(), alias)
+		if err != nil {
+			renderError(log, w, r, err)
+
+			return
+		}
+
+		render.JSON(w, r, response(link, clicks))
+	}
+}
+
+func renderError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound):
+		log.Info("url not found", sl.Err(err))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+	case errors.Is(err, context.Canceled):
+		log.Info("request canceled by client", sl.Err(err))
+		render.Status(r, resp.StatusClientClosedRequest)
+		render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Error("storage timeout", sl.Err(err))
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+	default:
+		log.Error("failed to get stats", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+	}
+}
+
+func response(link storage.Link, clicks storage.ClickStats) Response {
+	res := Response{
+		Response: resp.OK(),
+		Alias:    link.Alias,
+		Clicks:   clicks.Total,
+	}
+
+	for _, v := range link.Variants {
+		res.Variants = append(res.Variants, VariantStats{
+			Name:   v.Name,
+			URL:    v.URL,
+			Weight: v.Weight,
+			Clicks: clicks.Variants[v.Name],
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatsHandler(t *testing.T) {
+	abLink := storage.Link{
+		Alias: "landing",
+		URL:   "https://example.com/",
+		Variants: []storage.Variant{
+			{Name: "a", URL: "https://example.com/a", Weight: 50},
+			{Name: "b", URL: "https://example.com/b", Weight: 50},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		alias        string
+		link         storage.Link
+		linkError    error
+		clicks       storage.ClickStats
+		clicksError  error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "success: plain link",
+			alias:        "docs",
+			link:         storage.Link{Alias: "docs", URL: "https://example.com/docs"},
+			clicks:       storage.ClickStats{Total: 7, Variants: map[string]int{}},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","alias":"docs","clicks":7}`,
+		},
+		{
+			name:         "success: variant without clicks",
+			alias:        "landing",
+			link:         abLink,
+			clicks:       storage.ClickStats{Total: 3, Variants: map[string]int{"a": 3}},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","alias":"landing","clicks":3,"variants":[
+				{"name":"a","url":"https://example.com/a","weight":50,"clicks":3},
+				{"name":"b","url":"https://example.com/b","weight":50,"clicks":0}
+			]}`,
+		},
+		{
+			name:         "url not found",
+			alias:        "missing",
+			linkError:    storage.ErrURLNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":"Error","error":"not found","code":"not_found"}`,
+		},
+		{
+			name:         "stats error",
+			alias:        "docs",
+			link:         storage.Link{Alias: "docs", URL: "https://example.com/docs"},
+			clicksError:  errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":"Error","error":"internal error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStatsGetter := NewMockStatsGetter(t)
+			mockStatsGetter.On("GetURL", mock.Anything, tc.alias).Return(tc.link, tc.linkError).Once()
+			if tc.linkError == nil {
+				mockStatsGetter.On("GetClickStats", mock.Anything, tc.alias).Return(tc.clicks, tc.clicksError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/url/{alias}/stats", New(slogdiscard.NewDiscardLogger(), mockStatsGetter))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/url/"+tc.alias+"/stats", nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package update
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockURLUpdater is an autogenerated mock type for the URLUpdater type
+type MockURLUpdater struct {
+	mock.Mock
+}
+
+type MockURLUpdater_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockURLUpdater) EXPECT() *MockURLUpdater_Expecter {
+	return &MockURLUpdater_Expecter{mock: &_m.Mock}
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockURLUpdater) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURL")
+	}
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockURLUpdater_GetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURL'
+type MockURLUpdater_GetURL_Call struct {
+	*mock.Call
+}
+
+// GetURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockURLUpdater_Expecter) GetURL(ctx interface{}, alias interface{}) *MockURLUpdater_GetURL_Call {
+	return &MockURLUpdater_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
+}
+
+func (_c *MockURLUpdater_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockURLUpdater_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockURLUpdater_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockURLUpdater_GetURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockURLUpdater_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockURLUpdater_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateURL provides a mock function with given fields: ctx, link
+func (_m *MockURLUpdater) UpdateURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockURLUpdater_UpdateURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateURL'
+type MockURLUpdater_UpdateURL_Call struct {
+	*mock.Call
+}
+
+// UpdateURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockURLUpdater_Expecter) UpdateURL(ctx interface{}, link interface{}) *MockURLUpdater_UpdateURL_Call {
+	return &MockURLUpdater_UpdateURL_Call{Call: _e.mock.On("UpdateURL", ctx, link)}
+}
+
+func (_c *MockURLUpdater_UpdateURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockURLUpdater_UpdateURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Link))
+	})
+	return _c
+}
+
+func (_c *MockURLUpdater_UpdateURL_Call) Return(_a0 error) *MockURLUpdater_UpdateURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockURLUpdater_UpdateURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockURLUpdater_UpdateURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockURLUpdater creates a new instance of MockURLUpdater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockURLUpdater(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockURLUpdater {
+	mock := &MockURLUpdater{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
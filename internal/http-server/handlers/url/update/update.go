@@ -0,0 +1,303 @@
+package update
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/info"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/linkpassword"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Сколько раз PATCH без If-Match перечитывает ссылку, если ее
+// одновременно меняют другие запросы
+const maxAttempts = 3
+
+type URLUpdater interface {
+	GetURL(ctx context.Context, alias string) (storage.Link, error)
+	UpdateURL(ctx context.Context, link storage.Link) error
+}
+
+// New частично меняет ссылку. Тело - те же поля, что при создании; поля,
+// которых нет в теле, остаются как были, null и пустое значение сбрасывают
+// поле. "password": "" или null снимает защиту паролем. Алиас не меняется.
+// С заголовком If-Match ссылка меняется, только если ее версия (ETag из
+// ответа GET) не изменилась, иначе ответ 412.
+func New(log *slog.Logger, updater URLUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.update.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Error("failed to read request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidJSON, "failed to read request"))
+
+			return
+		}
+		if len(bytes.TrimSpace(body)) == 0 {
+			log.Info("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeEmptyRequest, "empty request"))
+
+			return
+		}
+
+		// Набор ключей нужен, чтобы отличить отсутствующий пароль от пустого
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			log.Info("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidJSON, "failed to decode request"))
+
+			return
+		}
+
+		// Читаем из primary: правка сразу после создания не должна получить
+		// 404 от отстающей реплики, а слияние - опираться на старые данные
+		ctx := storage.WithPrimary(r.Context())
+		ifMatch := r.Header.Get("If-Match")
+
+		// Тело накладывается на прочитанную ссылку, а UpdateURL сохраняет
+		// результат, только если версия ссылки с тех пор не изменилась.
+		// Если ссылку успел изменить другой запрос, чтение и слияние
+		// повторяются: одновременные правки разных полей не теряют друг
+		// друга. С If-Match клиент сам решает, что делать, и получает 412
+		for attempt := 1; ; attempt++ {
+			link, err := updater.GetURL(ctx, alias)
+			if err != nil {
+				renderError(log, w, r, err)
+
+				return
+			}
+
+			if ifMatch != "" && !etagMatches(ifMatch, info.ETag(link)) {
+				renderVersionMismatch(log, w, r, ifMatch)
+
+				return
+			}
+
+			updated, ok := merge(log, w, r, link, body, fields)
+			if !ok {
+				return
+			}
+
+			err = updater.UpdateURL(ctx, updated)
+			if errors.Is(err, storage.ErrVersionConflict) {
+				if ifMatch != "" {
+					renderVersionMismatch(log, w, r, ifMatch)
+
+					return
+				}
+				if attempt < maxAttempts {
+					log.Info("url changed concurrently, retrying", slog.Int("attempt", attempt))
+
+					continue
+				}
+
+				log.Warn("url keeps changing concurrently", slog.Int("attempts", attempt))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Error(resp.CodeUpdateConflict, "url is being updated concurrently, retry later"))
+
+				return
+			}
+			if errors.Is(err, storage.ErrUTMTemplateNotFound) {
+				log.Info("utm template not found", slog.String("template", updated.UTMTemplate))
+				render.Status(r, http.StatusUnprocessableEntity)
+				render.JSON(w, r, resp.Error(resp.CodeUTMTemplateNotFound, "utm template not found"))
+
+				return
+			}
+			if err != nil {
+				renderError(log, w, r, err)
+
+				return
+			}
+
+			break
+		}
+
+		log.Info("url updated", slog.String("alias", alias))
+
+		// Остаток кликов пересчитывает база, поэтому отдаем сохраненную ссылку
+		link, err := updater.GetURL(ctx, alias)
+		if err != nil {
+			renderError(log, w, r, err)
+
+			return
+		}
+
+		w.Header().Set("ETag", info.ETag(link))
+		render.JSON(w, r, info.NewResponse(link))
+	}
+}
+
+// merge накладывает тело запроса на ссылку и проверяет результат.
+// Если запрос неверный, отвечает клиенту сам и возвращает false.
+func merge(
+	log *slog.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	link storage.Link,
+	body []byte,
+	fields map[string]json.RawMessage,
+) (storage.Link, bool) {
+	req := save.RequestFromLink(link)
+	// Списки заменяются целиком: декодер дописывает элементы
+	// в существующий массив и смешал бы старые правила с новыми
+	if _, ok := fields["targeting"]; ok {
+		req.Targeting = nil
+	}
+	if _, ok := fields["variants"]; ok {
+		req.Variants = nil
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Info("failed to decode request body", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resp.CodeInvalidJSON, "failed to decode request"))
+
+		return storage.Link{}, false
+	}
+	clearNulls(reflect.ValueOf(&req).Elem(), fields)
+
+	if req.Alias != link.Alias {
+		log.Info("attempt to change alias", slog.String("alias", req.Alias))
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, resp.ValidationFailed(resp.FieldError{
+			Field:   "alias",
+			Rule:    "readonly",
+			Message: "alias cannot be changed",
+		}))
+
+		return storage.Link{}, false
+	}
+	// Алиас не меняется, а ссылки, созданные до ограничения набора
+	// символов в алиасе, тоже должны оставаться изменяемыми
+	req.Alias = ""
+
+	if details := req.Validate(); len(details) > 0 {
+		log.Info("invalid request", slog.Any("details", details))
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, resp.ValidationFailed(details...))
+
+		return storage.Link{}, false
+	}
+
+	updated := req.Link(link.Alias)
+	updated.Owner = link.Owner
+	updated.CreatedAt = link.CreatedAt
+	updated.PasswordHash = link.PasswordHash
+	updated.Version = link.Version
+	if _, ok := fields["password"]; ok {
+		updated.PasswordHash = ""
+		if req.Password != "" {
+			hash, err := linkpassword.Hash(req.Password)
+			if err != nil {
+				log.Error("failed to hash password", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error(resp.CodeInternal, "failed to update url"))
+
+				return storage.Link{}, false
+			}
+			updated.PasswordHash = hash
+		}
+	}
+
+	return updated, true
+}
+
+// etagMatches сравнивает If-Match с текущим ETag ссылки. Заголовок может
+// перечислять несколько версий через запятую или быть "*".
+func etagMatches(ifMatch, etag string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func renderVersionMismatch(log *slog.Logger, w http.ResponseWriter, r *http.Request, ifMatch string) {
+	log.Info("url version mismatch", slog.String("if_match", ifMatch))
+	render.Status(r, http.StatusPreconditionFailed)
+	render.JSON(w, r, resp.Error(resp.CodeVersionMismatch, "url was modified, fetch it again"))
+}
+
+// clearNulls сбрасывает поля, для которых в теле пришел null: декодер JSON
+// обнуляет только указатели и списки, а строки, числа и объекты оставляет
+// как были. Во вложенных объектах (utm, og) null сбрасывает отдельные поля.
+func clearNulls(v reflect.Value, fields map[string]json.RawMessage) {
+	typ := v.Type()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		raw, ok := fields[name]
+		if name == "" || name == "-" || !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		if string(bytes.TrimSpace(raw)) == "null" {
+			field.SetZero()
+
+			continue
+		}
+
+		if field.Kind() == reflect.Struct {
+			var nested map[string]json.RawMessage
+			if json.Unmarshal(raw, &nested) == nil {
+				clearNulls(field, nested)
+			}
+		}
+	}
+}
+
+func renderError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound):
+		log.Info("url not found", sl.Err(err))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+	case errors.Is(err, context.Canceled):
+		log.Info("request canceled by client", sl.Err(err))
+		render.Status(r, resp.StatusClientClosedRequest)
+		render.JSON(w, r, resp.Error(resp.CodeRequestCanceled, "request canceled"))
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Error("storage timeout", sl.Err(err))
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, resp.Error(resp.CodeStorageTimeout, "storage timeout"))
+	default:
+		log.Error("failed to update url", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+	}
+}
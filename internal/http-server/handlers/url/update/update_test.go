@@ -0,0 +1,267 @@
+package update
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUpdateHandler(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := storage.Link{
+		Alias:        "docs",
+		URL:          "https://example.com/docs",
+		Title:        "Docs",
+		Owner:        "admin",
+		RedirectType: http.StatusFound,
+		ForwardQuery: true,
+		MaxClicks:    100,
+		UTM:          storage.UTM{Source: "newsletter", Medium: "email"},
+		OG:           storage.OpenGraph{Title: "Docs", Description: "Read the docs"},
+		PasswordHash: "$2a$10$existing",
+		ActiveUntil:  until,
+		Targeting: []storage.TargetRule{
+			{OS: []string{"ios"}, URL: "https://apps.apple.com/app/id1"},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		body         string
+		getError     error
+		updateError  error
+		expected     func(link storage.Link) bool
+		expectedCode int
+	}{
+		{
+			name: "absent fields are kept, objects are merged",
+			body: `{"title": "Guide", "utm": {"campaign": "launch"}}`,
+			expected: func(link storage.Link) bool {
+				return link.Title == "Guide" && link.URL == current.URL &&
+					link.UTM == storage.UTM{Source: "newsletter", Medium: "email", Campaign: "launch"} &&
+					link.PasswordHash == current.PasswordHash && link.Owner == "admin" &&
+					link.ActiveUntil.Equal(until) && len(link.Targeting) == 1
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "lists are replaced",
+			body: `{"targeting": [{"url": "https://example.com/all"}]}`,
+			expected: func(link storage.Link) bool {
+				return len(link.Targeting) == 1 && link.Targeting[0].OS == nil &&
+					link.Targeting[0].URL == "https://example.com/all"
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "null clears a field",
+			body: `{"active_until": null, "targeting": null}`,
+			expected: func(link storage.Link) bool {
+				return link.ActiveUntil.IsZero() && link.Targeting == nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "null clears scalar fields",
+			body: `{"title": null, "redirect_type": null, "forward_query": null, "max_clicks": null, "password": null}`,
+			expected: func(link storage.Link) bool {
+				return link.Title == "" && link.RedirectType == 0 && !link.ForwardQuery &&
+					link.MaxClicks == 0 && link.PasswordHash == "" && link.URL == current.URL
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "null clears objects and their fields",
+			body: `{"utm": {"source": null, "campaign": "launch"}, "og": null}`,
+			expected: func(link storage.Link) bool {
+				return link.UTM == storage.UTM{Medium: "email", Campaign: "launch"} &&
+					link.OG == storage.OpenGraph{}
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "null url fails validation",
+			body:         `{"url": null}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "empty password removes protection",
+			body: `{"password": ""}`,
+			expected: func(link storage.Link) bool {
+				return link.PasswordHash == ""
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "new password is hashed",
+			body: `{"password": "s3cret"}`,
+			expected: func(link storage.Link) bool {
+				return bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte("s3cret")) == nil
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "empty body",
+			body:         ``,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "malformed json",
+			body:         `{"title": `,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "alias cannot be changed",
+			body:         `{"alias": "other"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid url",
+			body:         `{"url": "not a url"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "url not found",
+			body:         `{"title": "Guide"}`,
+			getError:     storage.ErrURLNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "unknown utm template",
+			body:         `{"utm_template": "missing"}`,
+			updateError:  storage.ErrUTMTemplateNotFound,
+			expected:     func(link storage.Link) bool { return link.UTMTemplate == "missing" },
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "storage error",
+			body:         `{"title": "Guide"}`,
+			updateError:  errors.New("database error"),
+			expected:     func(link storage.Link) bool { return true },
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUpdater := NewMockURLUpdater(t)
+			mockUpdater.On("GetURL", mock.Anything, "docs").Return(current, tc.getError).Maybe()
+			if tc.expected != nil {
+				mockUpdater.On("UpdateURL", mock.Anything, mock.MatchedBy(tc.expected)).Return(tc.updateError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/api/v1/links/{alias}", New(slogdiscard.NewDiscardLogger(), mockUpdater))
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/links/docs", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestUpdateHandler_Version(t *testing.T) {
+	current := storage.Link{Alias: "docs", URL: "https://example.com/docs", Version: 3}
+
+	cases := []struct {
+		name         string
+		ifMatch      string
+		updateErrors []error
+		expectedCode int
+		expectedTag  string
+	}{
+		{
+			name:         "matching if-match",
+			ifMatch:      `"3"`,
+			updateErrors: []error{nil},
+			expectedCode: http.StatusOK,
+			expectedTag:  `"3"`,
+		},
+		{
+			name:         "wildcard if-match",
+			ifMatch:      `*`,
+			updateErrors: []error{nil},
+			expectedCode: http.StatusOK,
+			expectedTag:  `"3"`,
+		},
+		{
+			name:         "stale if-match",
+			ifMatch:      `"2"`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "if-match and concurrent update",
+			ifMatch:      `"1", "3"`,
+			updateErrors: []error{storage.ErrVersionConflict},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "conflict is retried without if-match",
+			updateErrors: []error{storage.ErrVersionConflict, nil},
+			expectedCode: http.StatusOK,
+			expectedTag:  `"3"`,
+		},
+		{
+			name:         "persistent conflict",
+			updateErrors: []error{storage.ErrVersionConflict, storage.ErrVersionConflict, storage.ErrVersionConflict},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUpdater := NewMockURLUpdater(t)
+			mockUpdater.On("GetURL", mock.Anything, "docs").Return(current, nil)
+			for _, err := range tc.updateErrors {
+				mockUpdater.On("UpdateURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+					return link.Version == current.Version && link.Title == "Guide"
+				})).Return(err).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/api/v1/links/{alias}", New(slogdiscard.NewDiscardLogger(), mockUpdater))
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/links/docs", strings.NewReader(`{"title": "Guide"}`))
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedTag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestUpdateHandler_LegacyAlias(t *testing.T) {
+	// Алиас создан до ограничения набора символов и все равно изменяем
+	current := storage.Link{Alias: "old.docs", URL: "https://example.com/docs"}
+
+	mockUpdater := NewMockURLUpdater(t)
+	mockUpdater.On("GetURL", mock.Anything, "old.docs").Return(current, nil)
+	mockUpdater.On("UpdateURL", mock.Anything, mock.MatchedBy(func(link storage.Link) bool {
+		return link.Alias == "old.docs" && link.Title == "Guide"
+	})).Return(nil).Once()
+
+	r := chi.NewRouter()
+	r.Patch("/api/v1/links/{alias}", New(slogdiscard.NewDiscardLogger(), mockUpdater))
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/links/old.docs", strings.NewReader(`{"title": "Guide"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,56 @@
+package get
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response = api.UTMTemplate
+
+type UTMTemplateGetter interface {
+	GetUTMTemplate(ctx context.Context, name string) (storage.UTM, error)
+}
+
+func New(log *slog.Logger, getter UTMTemplateGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.utm.get.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		name := chi.URLParam(r, "name")
+
+		utm, err := getter.GetUTMTemplate(storage.WithPrimary(r.Context()), name)
+		if errors.Is(err, storage.ErrUTMTemplateNotFound) {
+			log.Info("utm template not found", slog.String("name", name))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error(resp.CodeNotFound, "not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get utm template", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "internal error"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Name:     name,
+			UTM:      utm,
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package get
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUTMTemplateHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		template     string
+		mockUTM      storage.UTM
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "success",
+			template:     "newsletter",
+			mockUTM:      storage.UTM{Source: "newsletter", Medium: "email"},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"OK","name":"newsletter","utm":{"source":"newsletter","medium":"email"}}`,
+		},
+		{
+			name:         "not found",
+			template:     "missing",
+			mockError:    storage.ErrUTMTemplateNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":"Error","error":"not found","code":"not_found"}`,
+		},
+	}
+
+	mockLog := slogdiscard.NewDiscardLogger()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockGetter := NewMockUTMTemplateGetter(t)
+			mockGetter.On("GetUTMTemplate", mock.Anything, tc.template).Return(tc.mockUTM, tc.mockError).Once()
+
+			r := chi.NewRouter()
+			r.Get("/utm-templates/{name}", New(mockLog, mockGetter))
+
+			req := httptest.NewRequest(http.MethodGet, "/utm-templates/"+tc.template, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
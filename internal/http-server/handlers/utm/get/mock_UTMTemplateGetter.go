@@ -0,0 +1,94 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package get
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockUTMTemplateGetter is an autogenerated mock type for the UTMTemplateGetter type
+type MockUTMTemplateGetter struct {
+	mock.Mock
+}
+
+type MockUTMTemplateGetter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUTMTemplateGetter) EXPECT() *MockUTMTemplateGetter_Expecter {
+	return &MockUTMTemplateGetter_Expecter{mock: &_m.Mock}
+}
+
+// GetUTMTemplate provides a mock function with given fields: ctx, name
+func (_m *MockUTMTemplateGetter) GetUTMTemplate(ctx context.Context, name string) (storage.UTM, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUTMTemplate")
+	}
+
+	var r0 storage.UTM
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.UTM, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.UTM); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(storage.UTM)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUTMTemplateGetter_GetUTMTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUTMTemplate'
+type MockUTMTemplateGetter_GetUTMTemplate_Call struct {
+	*mock.Call
+}
+
+// GetUTMTemplate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockUTMTemplateGetter_Expecter) GetUTMTemplate(ctx interface{}, name interface{}) *MockUTMTemplateGetter_GetUTMTemplate_Call {
+	return &MockUTMTemplateGetter_GetUTMTemplate_Call{Call: _e.mock.On("GetUTMTemplate", ctx, name)}
+}
+
+func (_c *MockUTMTemplateGetter_GetUTMTemplate_Call) Run(run func(ctx context.Context, name string)) *MockUTMTemplateGetter_GetUTMTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUTMTemplateGetter_GetUTMTemplate_Call) Return(_a0 storage.UTM, _a1 error) *MockUTMTemplateGetter_GetUTMTemplate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUTMTemplateGetter_GetUTMTemplate_Call) RunAndReturn(run func(context.Context, string) (storage.UTM, error)) *MockUTMTemplateGetter_GetUTMTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUTMTemplateGetter creates a new instance of MockUTMTemplateGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUTMTemplateGetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUTMTemplateGetter {
+	mock := &MockUTMTemplateGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,85 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package save
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockUTMTemplateSaver is an autogenerated mock type for the UTMTemplateSaver type
+type MockUTMTemplateSaver struct {
+	mock.Mock
+}
+
+type MockUTMTemplateSaver_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUTMTemplateSaver) EXPECT() *MockUTMTemplateSaver_Expecter {
+	return &MockUTMTemplateSaver_Expecter{mock: &_m.Mock}
+}
+
+// SaveUTMTemplate provides a mock function with given fields: ctx, name, utm
+func (_m *MockUTMTemplateSaver) SaveUTMTemplate(ctx context.Context, name string, utm storage.UTM) error {
+	ret := _m.Called(ctx, name, utm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUTMTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, storage.UTM) error); ok {
+		r0 = rf(ctx, name, utm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUTMTemplateSaver_SaveUTMTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUTMTemplate'
+type MockUTMTemplateSaver_SaveUTMTemplate_Call struct {
+	*mock.Call
+}
+
+// SaveUTMTemplate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - utm storage.UTM
+func (_e *MockUTMTemplateSaver_Expecter) SaveUTMTemplate(ctx interface{}, name interface{}, utm interface{}) *MockUTMTemplateSaver_SaveUTMTemplate_Call {
+	return &MockUTMTemplateSaver_SaveUTMTemplate_Call{Call: _e.mock.On("SaveUTMTemplate", ctx, name, utm)}
+}
+
+func (_c *MockUTMTemplateSaver_SaveUTMTemplate_Call) Run(run func(ctx context.Context, name string, utm storage.UTM)) *MockUTMTemplateSaver_SaveUTMTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(storage.UTM))
+	})
+	return _c
+}
+
+func (_c *MockUTMTemplateSaver_SaveUTMTemplate_Call) Return(_a0 error) *MockUTMTemplateSaver_SaveUTMTemplate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUTMTemplateSaver_SaveUTMTemplate_Call) RunAndReturn(run func(context.Context, string, storage.UTM) error) *MockUTMTemplateSaver_SaveUTMTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUTMTemplateSaver creates a new instance of MockUTMTemplateSaver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUTMTemplateSaver(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUTMTemplateSaver {
+	mock := &MockUTMTemplateSaver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,101 @@
+package save
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/sl"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/Tbits007/url-shortener/pkg/api"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	validator "github.com/go-playground/validator/v10"
+)
+
+const maxNameLength = 64
+
+var validate = resp.NewValidator()
+
+type Request struct {
+	storage.UTM
+}
+
+type Response = api.UTMTemplateSaveResponse
+
+type UTMTemplateSaver interface {
+	SaveUTMTemplate(ctx context.Context, name string, utm storage.UTM) error
+}
+
+// New создает или заменяет именованный шаблон UTM-меток,
+// на который потом можно сослаться при создании ссылки.
+func New(log *slog.Logger, saver UTMTemplateSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.utm.save.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		).With(tracing.LogAttrs(r.Context())...)
+
+		name := chi.URLParam(r, "name")
+		if name == "" || len(name) > maxNameLength {
+			log.Info("invalid template name", slog.String("name", name))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidParameter, "invalid template name"))
+			return
+		}
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeEmptyRequest, "empty request"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.CodeInvalidJSON, "failed to decode request"))
+			return
+		}
+
+		// Шаблон встроен в запрос без своего ключа, поэтому проверяется
+		// сам storage.UTM: так пути полей в details совпадают с JSON
+		if err := validate.Struct(req.UTM); err != nil {
+			log.Error("invalid request", sl.Err(err))
+			render.Status(r, http.StatusUnprocessableEntity)
+			render.JSON(w, r, resp.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		if req.UTM == (storage.UTM{}) {
+			log.Info("template is empty", slog.String("name", name))
+			render.Status(r, http.StatusUnprocessableEntity)
+			render.JSON(w, r, resp.Error(resp.CodeValidationFailed, "template must set at least one utm field"))
+			return
+		}
+
+		err = saver.SaveUTMTemplate(r.Context(), name, req.UTM)
+		if err != nil {
+			log.Error("failed to save utm template", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error(resp.CodeInternal, "failed to save utm template"))
+			return
+		}
+
+		log.Info("utm template saved", slog.String("name", name))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Name:     name,
+		})
+	}
+}
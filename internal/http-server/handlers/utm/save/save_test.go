@@ -0,0 +1,82 @@
+package save
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveUTMTemplateHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		template     string
+		body         string
+		expectedUTM  *storage.UTM
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "success",
+			template:     "newsletter",
+			body:         `{"source": "newsletter", "medium": "email"}`,
+			expectedUTM:  &storage.UTM{Source: "newsletter", Medium: "email"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "empty template",
+			template:     "empty",
+			body:         `{}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "empty body",
+			template:     "newsletter",
+			body:         ``,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "name too long",
+			template:     strings.Repeat("a", maxNameLength+1),
+			body:         `{"source": "newsletter"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "storage error",
+			template:     "newsletter",
+			body:         `{"source": "newsletter"}`,
+			expectedUTM:  &storage.UTM{Source: "newsletter"},
+			mockError:    errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	mockLog := slogdiscard.NewDiscardLogger()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSaver := NewMockUTMTemplateSaver(t)
+			if tc.expectedUTM != nil {
+				mockSaver.On("SaveUTMTemplate", mock.Anything, tc.template, *tc.expectedUTM).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Put("/utm-templates/{name}", New(mockLog, mockSaver))
+
+			req := httptest.NewRequest(http.MethodPut, "/utm-templates/"+tc.template, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/basicauth"
+	"github.com/go-chi/render"
+)
+
+// BasicAuth проверяет учетные данные как middleware.BasicAuth из chi,
+// но отказ отдает тем же JSON, что и остальные ошибки API, с кодом unauthorized.
+func BasicAuth(realm string, creds map[string]string) func(next http.Handler) http.Handler {
+	challenge := fmt.Sprintf("Basic realm=%q", realm)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !basicauth.Check(creds, user, pass) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error(resp.CodeUnauthorized, "unauthorized"))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
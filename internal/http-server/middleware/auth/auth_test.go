@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuth(t *testing.T) {
+	cases := []struct {
+		name         string
+		user         string
+		password     string
+		noAuth       bool
+		expectedCode int
+	}{
+		{
+			name:         "valid credentials",
+			user:         "admin",
+			password:     "secret",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "wrong password",
+			user:         "admin",
+			password:     "wrong",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unknown user",
+			user:         "guest",
+			password:     "secret",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "no credentials",
+			noAuth:       true,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	handler := BasicAuth("url-shortener", map[string]string{"admin": "secret"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/url/docs", nil)
+			if !tc.noAuth {
+				req.SetBasicAuth(tc.user, tc.password)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedCode == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="url-shortener"`, w.Header().Get("WWW-Authenticate"))
+				assert.JSONEq(t, `{"status":"Error","error":"unauthorized","code":"unauthorized"}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// New помечает ответы маршрута устаревшими: Deprecation (RFC 9745) с датой,
+// с которой маршрут устарел, Sunset (RFC 8594) с датой удаления и Link
+// на документацию замены. Сам ответ не меняется.
+func New(deprecatedAt, sunset time.Time, successor string) func(next http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunsetDate)
+			}
+			if successor != "" {
+				w.Header().Add("Link", link)
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
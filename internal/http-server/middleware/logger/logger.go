@@ -4,6 +4,7 @@ import (
     "net/http"
     "time"
 
+    "github.com/Tbits007/url-shortener/internal/lib/tracing"
     "github.com/go-chi/chi/v5/middleware"
     "log/slog"
 )
@@ -25,7 +26,7 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
                 slog.String("remote_addr", r.RemoteAddr),
                 slog.String("user_agent", r.UserAgent()),
                 slog.String("request_id", middleware.GetReqID(r.Context())),
-            )
+            ).With(tracing.LogAttrs(r.Context())...)
             
             // создаем обертку вокруг `http.ResponseWriter`
             // для получения сведений об ответе
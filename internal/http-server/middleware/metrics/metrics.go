@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Маршрут для запросов, которые не совпали ни с одним шаблоном.
+// Сырой путь в метку не пишем: это неограниченная кардинальность.
+const unmatchedRoute = "unmatched"
+
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+
+			next.ServeHTTP(ww, r)
+
+			// Шаблон маршрута известен только после того, как chi выполнил роутинг
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					route = pattern
+				}
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			statusClass := metrics.StatusClass(status)
+
+			metrics.HTTPRequestsTotal.WithLabelValues(route, r.Method, statusClass).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, statusClass).
+				Observe(time.Since(t1).Seconds())
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
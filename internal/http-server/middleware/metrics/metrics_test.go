@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(New())
+	r.Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	})
+
+	redirects := metrics.HTTPRequestsTotal.WithLabelValues("/{alias}", http.MethodGet, "3xx")
+	unmatched := metrics.HTTPRequestsTotal.WithLabelValues(unmatchedRoute, http.MethodGet, "4xx")
+
+	before := testutil.ToFloat64(redirects)
+	beforeUnmatched := testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/first", "/second", "/a/b/c"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Разные алиасы должны попасть в один временной ряд с шаблоном маршрута
+	assert.Equal(t, before+2, testutil.ToFloat64(redirects))
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched))
+}
@@ -0,0 +1,652 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package router
+
+import (
+	context "context"
+
+	storage "github.com/Tbits007/url-shortener/internal/storage"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStorage is an autogenerated mock type for the Storage type
+type MockStorage struct {
+	mock.Mock
+}
+
+type MockStorage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorage) EXPECT() *MockStorage_Expecter {
+	return &MockStorage_Expecter{mock: &_m.Mock}
+}
+
+// CheckMigrations provides a mock function with given fields: ctx
+func (_m *MockStorage) CheckMigrations(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckMigrations")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_CheckMigrations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckMigrations'
+type MockStorage_CheckMigrations_Call struct {
+	*mock.Call
+}
+
+// CheckMigrations is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockStorage_Expecter) CheckMigrations(ctx interface{}) *MockStorage_CheckMigrations_Call {
+	return &MockStorage_CheckMigrations_Call{Call: _e.mock.On("CheckMigrations", ctx)}
+}
+
+func (_c *MockStorage_CheckMigrations_Call) Run(run func(ctx context.Context)) *MockStorage_CheckMigrations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockStorage_CheckMigrations_Call) Return(_a0 error) *MockStorage_CheckMigrations_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_CheckMigrations_Call) RunAndReturn(run func(context.Context) error) *MockStorage_CheckMigrations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeClick provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) ConsumeClick(ctx context.Context, alias string) (int, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeClick")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_ConsumeClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeClick'
+type MockStorage_ConsumeClick_Call struct {
+	*mock.Call
+}
+
+// ConsumeClick is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) ConsumeClick(ctx interface{}, alias interface{}) *MockStorage_ConsumeClick_Call {
+	return &MockStorage_ConsumeClick_Call{Call: _e.mock.On("ConsumeClick", ctx, alias)}
+}
+
+func (_c *MockStorage_ConsumeClick_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_ConsumeClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_ConsumeClick_Call) Return(_a0 int, _a1 error) *MockStorage_ConsumeClick_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_ConsumeClick_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockStorage_ConsumeClick_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteURL provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) DeleteURL(ctx context.Context, alias string) error {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_DeleteURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteURL'
+type MockStorage_DeleteURL_Call struct {
+	*mock.Call
+}
+
+// DeleteURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) DeleteURL(ctx interface{}, alias interface{}) *MockStorage_DeleteURL_Call {
+	return &MockStorage_DeleteURL_Call{Call: _e.mock.On("DeleteURL", ctx, alias)}
+}
+
+func (_c *MockStorage_DeleteURL_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_DeleteURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_DeleteURL_Call) Return(_a0 error) *MockStorage_DeleteURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_DeleteURL_Call) RunAndReturn(run func(context.Context, string) error) *MockStorage_DeleteURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetClickStats provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) GetClickStats(ctx context.Context, alias string) (storage.ClickStats, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClickStats")
+	}
+
+	var r0 storage.ClickStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.ClickStats, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.ClickStats); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.ClickStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClickStats'
+type MockStorage_GetClickStats_Call struct {
+	*mock.Call
+}
+
+// GetClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) GetClickStats(ctx interface{}, alias interface{}) *MockStorage_GetClickStats_Call {
+	return &MockStorage_GetClickStats_Call{Call: _e.mock.On("GetClickStats", ctx, alias)}
+}
+
+func (_c *MockStorage_GetClickStats_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_GetClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetClickStats_Call) Return(_a0 storage.ClickStats, _a1 error) *MockStorage_GetClickStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetClickStats_Call) RunAndReturn(run func(context.Context, string) (storage.ClickStats, error)) *MockStorage_GetClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURL provides a mock function with given fields: ctx, alias
+func (_m *MockStorage) GetURL(ctx context.Context, alias string) (storage.Link, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURL")
+	}
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.Link, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.Link); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURL'
+type MockStorage_GetURL_Call struct {
+	*mock.Call
+}
+
+// GetURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockStorage_Expecter) GetURL(ctx interface{}, alias interface{}) *MockStorage_GetURL_Call {
+	return &MockStorage_GetURL_Call{Call: _e.mock.On("GetURL", ctx, alias)}
+}
+
+func (_c *MockStorage_GetURL_Call) Run(run func(ctx context.Context, alias string)) *MockStorage_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetURL_Call) Return(_a0 storage.Link, _a1 error) *MockStorage_GetURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetURL_Call) RunAndReturn(run func(context.Context, string) (storage.Link, error)) *MockStorage_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUTMTemplate provides a mock function with given fields: ctx, name
+func (_m *MockStorage) GetUTMTemplate(ctx context.Context, name string) (storage.UTM, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUTMTemplate")
+	}
+
+	var r0 storage.UTM
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (storage.UTM, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) storage.UTM); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(storage.UTM)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetUTMTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUTMTemplate'
+type MockStorage_GetUTMTemplate_Call struct {
+	*mock.Call
+}
+
+// GetUTMTemplate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockStorage_Expecter) GetUTMTemplate(ctx interface{}, name interface{}) *MockStorage_GetUTMTemplate_Call {
+	return &MockStorage_GetUTMTemplate_Call{Call: _e.mock.On("GetUTMTemplate", ctx, name)}
+}
+
+func (_c *MockStorage_GetUTMTemplate_Call) Run(run func(ctx context.Context, name string)) *MockStorage_GetUTMTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetUTMTemplate_Call) Return(_a0 storage.UTM, _a1 error) *MockStorage_GetUTMTemplate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetUTMTemplate_Call) RunAndReturn(run func(context.Context, string) (storage.UTM, error)) *MockStorage_GetUTMTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListURLs provides a mock function with given fields: ctx, filter
+func (_m *MockStorage) ListURLs(ctx context.Context, filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListURLs")
+	}
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ListFilter) []storage.Link); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_ListURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListURLs'
+type MockStorage_ListURLs_Call struct {
+	*mock.Call
+}
+
+// ListURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter storage.ListFilter
+func (_e *MockStorage_Expecter) ListURLs(ctx interface{}, filter interface{}) *MockStorage_ListURLs_Call {
+	return &MockStorage_ListURLs_Call{Call: _e.mock.On("ListURLs", ctx, filter)}
+}
+
+func (_c *MockStorage_ListURLs_Call) Run(run func(ctx context.Context, filter storage.ListFilter)) *MockStorage_ListURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.ListFilter))
+	})
+	return _c
+}
+
+func (_c *MockStorage_ListURLs_Call) Return(_a0 []storage.Link, _a1 error) *MockStorage_ListURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_ListURLs_Call) RunAndReturn(run func(context.Context, storage.ListFilter) ([]storage.Link, error)) *MockStorage_ListURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *MockStorage) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type MockStorage_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockStorage_Expecter) Ping(ctx interface{}) *MockStorage_Ping_Call {
+	return &MockStorage_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *MockStorage_Ping_Call) Run(run func(ctx context.Context)) *MockStorage_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockStorage_Ping_Call) Return(_a0 error) *MockStorage_Ping_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_Ping_Call) RunAndReturn(run func(context.Context) error) *MockStorage_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordClick provides a mock function with given fields: ctx, click
+func (_m *MockStorage) RecordClick(ctx context.Context, click storage.Click) error {
+	ret := _m.Called(ctx, click)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Click) error); ok {
+		r0 = rf(ctx, click)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_RecordClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordClick'
+type MockStorage_RecordClick_Call struct {
+	*mock.Call
+}
+
+// RecordClick is a helper method to define mock.On call
+//   - ctx context.Context
+//   - click storage.Click
+func (_e *MockStorage_Expecter) RecordClick(ctx interface{}, click interface{}) *MockStorage_RecordClick_Call {
+	return &MockStorage_RecordClick_Call{Call: _e.mock.On("RecordClick", ctx, click)}
+}
+
+func (_c *MockStorage_RecordClick_Call) Run(run func(ctx context.Context, click storage.Click)) *MockStorage_RecordClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Click))
+	})
+	return _c
+}
+
+func (_c *MockStorage_RecordClick_Call) Return(_a0 error) *MockStorage_RecordClick_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_RecordClick_Call) RunAndReturn(run func(context.Context, storage.Click) error) *MockStorage_RecordClick_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveURL provides a mock function with given fields: ctx, link
+func (_m *MockStorage) SaveURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_SaveURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveURL'
+type MockStorage_SaveURL_Call struct {
+	*mock.Call
+}
+
+// SaveURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockStorage_Expecter) SaveURL(ctx interface{}, link interface{}) *MockStorage_SaveURL_Call {
+	return &MockStorage_SaveURL_Call{Call: _e.mock.On("SaveURL", ctx, link)}
+}
+
+func (_c *MockStorage_SaveURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockStorage_SaveURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Link))
+	})
+	return _c
+}
+
+func (_c *MockStorage_SaveURL_Call) Return(_a0 error) *MockStorage_SaveURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_SaveURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockStorage_SaveURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUTMTemplate provides a mock function with given fields: ctx, name, utm
+func (_m *MockStorage) SaveUTMTemplate(ctx context.Context, name string, utm storage.UTM) error {
+	ret := _m.Called(ctx, name, utm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUTMTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, storage.UTM) error); ok {
+		r0 = rf(ctx, name, utm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_SaveUTMTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUTMTemplate'
+type MockStorage_SaveUTMTemplate_Call struct {
+	*mock.Call
+}
+
+// SaveUTMTemplate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - utm storage.UTM
+func (_e *MockStorage_Expecter) SaveUTMTemplate(ctx interface{}, name interface{}, utm interface{}) *MockStorage_SaveUTMTemplate_Call {
+	return &MockStorage_SaveUTMTemplate_Call{Call: _e.mock.On("SaveUTMTemplate", ctx, name, utm)}
+}
+
+func (_c *MockStorage_SaveUTMTemplate_Call) Run(run func(ctx context.Context, name string, utm storage.UTM)) *MockStorage_SaveUTMTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(storage.UTM))
+	})
+	return _c
+}
+
+func (_c *MockStorage_SaveUTMTemplate_Call) Return(_a0 error) *MockStorage_SaveUTMTemplate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_SaveUTMTemplate_Call) RunAndReturn(run func(context.Context, string, storage.UTM) error) *MockStorage_SaveUTMTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateURL provides a mock function with given fields: ctx, link
+func (_m *MockStorage) UpdateURL(ctx context.Context, link storage.Link) error {
+	ret := _m.Called(ctx, link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.Link) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_UpdateURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateURL'
+type MockStorage_UpdateURL_Call struct {
+	*mock.Call
+}
+
+// UpdateURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link storage.Link
+func (_e *MockStorage_Expecter) UpdateURL(ctx interface{}, link interface{}) *MockStorage_UpdateURL_Call {
+	return &MockStorage_UpdateURL_Call{Call: _e.mock.On("UpdateURL", ctx, link)}
+}
+
+func (_c *MockStorage_UpdateURL_Call) Run(run func(ctx context.Context, link storage.Link)) *MockStorage_UpdateURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.Link))
+	})
+	return _c
+}
+
+func (_c *MockStorage_UpdateURL_Call) Return(_a0 error) *MockStorage_UpdateURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_UpdateURL_Call) RunAndReturn(run func(context.Context, storage.Link) error) *MockStorage_UpdateURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorage creates a new instance of MockStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorage {
+	mock := &MockStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
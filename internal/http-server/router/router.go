@@ -0,0 +1,134 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/health"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/openapi"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/info"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/list"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/qr"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/redirect"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/remove"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/stats"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/update"
+	utmGet "github.com/Tbits007/url-shortener/internal/http-server/handlers/utm/get"
+	utmSave "github.com/Tbits007/url-shortener/internal/http-server/handlers/utm/save"
+	"github.com/Tbits007/url-shortener/internal/http-server/middleware/auth"
+	"github.com/Tbits007/url-shortener/internal/http-server/middleware/deprecation"
+	"github.com/Tbits007/url-shortener/internal/http-server/middleware/logger"
+	mwMetrics "github.com/Tbits007/url-shortener/internal/http-server/middleware/metrics"
+	mwTracing "github.com/Tbits007/url-shortener/internal/http-server/middleware/tracing"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const apiPrefix = "/api/v1"
+
+// Storage - все, что обработчикам нужно от хранилища.
+type Storage interface {
+	save.URLSaver
+	info.URLGetter
+	list.URLLister
+	stats.StatsGetter
+	qr.URLGetter
+	redirect.URLGetter
+	update.URLUpdater
+	remove.URLDeleter
+	utmSave.UTMTemplateSaver
+	utmGet.UTMTemplateGetter
+	health.Storage
+}
+
+// Deps - зависимости обработчиков, которые создаются при старте сервиса.
+type Deps struct {
+	Storage   Storage
+	Guard     redirect.PasswordGuard
+	Countries redirect.CountryResolver
+	Pages     redirect.PageRenderer
+	// Выставляется при остановке сервера, чтобы /readyz перестал отвечать 200
+	ShuttingDown *atomic.Bool
+}
+
+// New собирает все маршруты сервиса. Вынесен из main, чтобы тесты
+// проверяли тот же роутер, что обслуживает запросы.
+func New(log *slog.Logger, cfg *config.Config, deps Deps) chi.Router {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID) // Добавляет request_id в каждый запрос, для трейсинга
+	router.Use(mwTracing.New())      // Спан на каждый запрос с учетом входящего traceparent
+	router.Use(logger.New(log))      // Логирование всех запросов
+	router.Use(mwMetrics.New())      // Метрики запросов в разрезе шаблонов маршрутов
+	router.Use(middleware.Recoverer) // Если где-то внутри сервера (обработчика запроса) произойдет паника, приложение не должно упасть
+	router.Use(middleware.URLFormat) // Парсер URLов поступающих запросов
+
+	basicAuth := auth.BasicAuth("url-shortener", map[string]string{
+		cfg.HTTPServer.User: cfg.HTTPServer.Password,
+	})
+
+	router.Route(apiPrefix, func(r chi.Router) {
+		r.Use(basicAuth)
+
+		r.Get("/links", list.New(log, deps.Storage))
+		r.Post("/links", save.NewCreated(log, deps.Storage, apiPrefix+"/links"))
+		r.Get("/links/{alias}", info.New(log, deps.Storage))
+		r.Patch("/links/{alias}", update.New(log, deps.Storage))
+		r.Delete("/links/{alias}", remove.New(log, deps.Storage))
+		r.Get("/links/{alias}/stats", stats.New(log, deps.Storage))
+		r.Get("/links/{alias}/qr", qr.New(log, deps.Storage, cfg.HTTPServer.BaseURL))
+
+		r.Put("/utm-templates/{name}", utmSave.New(log, deps.Storage))
+		r.Get("/utm-templates/{name}", utmGet.New(log, deps.Storage))
+	})
+
+	// Маршруты до /api/v1. Делят пространство имен с алиасами, поэтому
+	// устарели; отвечают как раньше, но с датой удаления в Sunset
+	legacy := func(successor string) func(http.Handler) http.Handler {
+		return deprecation.New(cfg.API.LegacyDeprecatedAt, cfg.API.LegacySunset, apiPrefix+successor)
+	}
+	router.Group(func(r chi.Router) {
+		r.Use(legacy("/links"), basicAuth)
+
+		r.Post("/saveURL", save.New(log, deps.Storage))
+		r.Get("/url/{alias}", info.New(log, deps.Storage))
+		r.Get("/url/{alias}/stats", stats.New(log, deps.Storage))
+		r.Get("/url/{alias}/qr", qr.New(log, deps.Storage, cfg.HTTPServer.BaseURL))
+	})
+	router.Group(func(r chi.Router) {
+		r.Use(legacy("/utm-templates"), basicAuth)
+
+		r.Put("/utm-templates/{name}", utmSave.New(log, deps.Storage))
+		r.Get("/utm-templates/{name}", utmGet.New(log, deps.Storage))
+	})
+
+	// Пробы для балансировщика. Статические маршруты в chi имеют приоритет
+	// над /{alias}, а save не дает создать алиас с таким именем.
+	router.Get("/healthz", health.NewLiveness())
+	router.Get("/readyz", health.NewReadiness(log, deps.Storage, deps.ShuttingDown, cfg.Health.ReadinessTimeout))
+	router.Get("/metrics", metrics.Handler().ServeHTTP)
+
+	// URLFormat отрезает расширение до роутинга, поэтому /openapi.json
+	// приходит сюда как /openapi с форматом json
+	router.Get("/openapi", openapi.New())
+	if cfg.HTTPServer.SwaggerUI {
+		router.Get("/openapi/ui", openapi.NewUI("/openapi.json"))
+	}
+
+	redirectHandler := redirect.New(log, deps.Storage, deps.Guard, deps.Countries, deps.Pages, cfg.Redirect, time.Now)
+	router.Get("/{alias}", redirectHandler)
+	router.Get("/{alias}/*", redirectHandler) // Ссылки с передачей остатка пути
+	// Остальные методы: POST - форма пароля защищенных ссылок, а ссылки
+	// 307/308 перенаправляют любой метод. Прочим обработчик ответит 405
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		router.Method(method, "/{alias}", redirectHandler)
+		router.Method(method, "/{alias}/*", redirectHandler)
+	}
+
+	return router
+}
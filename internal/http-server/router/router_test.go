@@ -0,0 +1,308 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/health"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/openapi"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/info"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/list"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/save"
+	"github.com/Tbits007/url-shortener/internal/http-server/handlers/url/stats"
+	utmGet "github.com/Tbits007/url-shortener/internal/http-server/handlers/utm/get"
+	utmSave "github.com/Tbits007/url-shortener/internal/http-server/handlers/utm/save"
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schema struct {
+	Ref        string            `json:"$ref"`
+	AllOf      []schema          `json:"allOf"`
+	Properties map[string]schema `json:"properties"`
+	Required   []string          `json:"required"`
+	Nullable   bool              `json:"nullable"`
+	Enum       []any             `json:"enum"`
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]schema `json:"schemas"`
+	} `json:"components"`
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	doc := loadSpec(t)
+
+	var documented []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	var registered []string
+	err := chi.Walk(newTestRouter(t), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered = append(registered, method+" "+specPath(route))
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(documented)
+	sort.Strings(registered)
+	assert.Equal(t, registered, documented)
+}
+
+func TestSpecMatchesTypes(t *testing.T) {
+	doc := loadSpec(t)
+
+	// Схемы, которые описывают типы запросов и ответов обработчиков
+	types := map[string]any{
+		"Response":                resp.Response{},
+		"FieldError":              resp.FieldError{},
+		"SaveRequest":             save.Request{},
+		"UpdateRequest":           save.Request{},
+		"SaveResponse":            save.Response{},
+		"LinkInfo":                info.Response{},
+		"LinkList":                list.Response{},
+		"Stats":                   stats.Response{},
+		"VariantStats":            stats.VariantStats{},
+		"UTM":                     storage.UTM{},
+		"UTMTemplateSaveResponse": utmSave.Response{},
+		"UTMTemplateResponse":     utmGet.Response{},
+		"TargetRule":              storage.TargetRule{},
+		"Variant":                 storage.Variant{},
+		"OpenGraph":               storage.OpenGraph{},
+		"Health":                  health.Response{},
+		"HealthCheck":             health.Check{},
+	}
+
+	for name, v := range types {
+		t.Run(name, func(t *testing.T) {
+			s, ok := doc.Components.Schemas[name]
+			require.True(t, ok, "schema %s is not documented", name)
+
+			assert.ElementsMatch(t, jsonFields(reflect.TypeOf(v)), properties(doc, s))
+		})
+	}
+}
+
+func TestSpecUpdateRequest(t *testing.T) {
+	doc := loadSpec(t)
+
+	var patch struct {
+		RequestBody struct {
+			Content map[string]struct {
+				Schema schema `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+	}
+	require.NoError(t, json.Unmarshal(doc.Paths["/api/v1/links/{alias}"]["patch"], &patch))
+	assert.Equal(t, "#/components/schemas/UpdateRequest", patch.RequestBody.Content["application/json"].Schema.Ref)
+
+	// PATCH меняет только переданные поля, а null их сбрасывает.
+	// Сбросить нельзя только адрес и алиас: это ошибка валидации
+	s := doc.Components.Schemas["UpdateRequest"]
+	assert.Empty(t, s.Required)
+	for name, prop := range s.Properties {
+		assert.Equal(t, name != "url" && name != "alias", prop.Nullable, name)
+		if len(prop.Enum) > 0 {
+			assert.Equal(t, prop.Nullable, slices.Contains(prop.Enum, nil), name)
+		}
+	}
+}
+
+func TestSpecErrorCodes(t *testing.T) {
+	doc := loadSpec(t)
+
+	codes := []any{
+		resp.CodeEmptyRequest, resp.CodeInvalidJSON, resp.CodeInvalidParameter,
+		resp.CodeUnauthorized, resp.CodeNotFound, resp.CodeNotYetAvailable,
+		resp.CodeMethodNotAllowed, resp.CodeAliasExists, resp.CodeAliasReserved,
+		resp.CodeGone, resp.CodeValidationFailed, resp.CodeUTMTemplateNotFound,
+		resp.CodeUpdateConflict, resp.CodeVersionMismatch,
+		resp.CodeRequestCanceled, resp.CodeInternal, resp.CodeStorageTimeout,
+		resp.CodeNotReady,
+	}
+
+	assert.ElementsMatch(t, codes, doc.Components.Schemas["Response"].Properties["code"].Enum)
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	r := newTestRouter(t)
+
+	cases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedType string
+	}{
+		{
+			name:         "spec",
+			path:         "/openapi.json",
+			expectedCode: http.StatusOK,
+			expectedType: "application/json",
+		},
+		{
+			name:         "unsupported format",
+			path:         "/openapi.yaml",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+		},
+		{
+			name:         "swagger ui",
+			path:         "/openapi/ui",
+			expectedCode: http.StatusOK,
+			expectedType: "text/html",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), tc.expectedType)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, openapi.Spec(), w.Body.Bytes())
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r := newTestRouter(t)
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		deprecated bool
+	}{
+		{name: "save", method: http.MethodPost, path: "/saveURL", deprecated: true},
+		{name: "info", method: http.MethodGet, path: "/url/docs", deprecated: true},
+		{name: "utm template", method: http.MethodGet, path: "/utm-templates/newsletter", deprecated: true},
+		{name: "v1 links", method: http.MethodPost, path: "/api/v1/links"},
+		{name: "v1 link", method: http.MethodGet, path: "/api/v1/links/docs"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Без учетных данных: заголовки должны быть и на отказе авторизации
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			if tc.deprecated {
+				assert.NotEmpty(t, w.Header().Get("Deprecation"))
+				assert.NotEmpty(t, w.Header().Get("Sunset"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func newTestRouter(t *testing.T) chi.Router {
+	t.Helper()
+
+	cfg := &config.Config{
+		HTTPServer: config.HTTPServer{
+			User:      "admin",
+			Password:  "secret",
+			SwaggerUI: true,
+		},
+		API: config.API{
+			LegacyDeprecatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			LegacySunset:       time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Обработчики не вызываются, поэтому хранилище не нужно
+	return New(slogdiscard.NewDiscardLogger(), cfg, Deps{ShuttingDown: new(atomic.Bool)})
+}
+
+func loadSpec(t *testing.T) document {
+	t.Helper()
+
+	var doc document
+	require.NoError(t, json.Unmarshal(openapi.Spec(), &doc))
+
+	return doc
+}
+
+// specPath переводит шаблон chi в путь OpenAPI.
+func specPath(route string) string {
+	// Остаток пути у chi - "*", в OpenAPI это обычный параметр
+	if prefix, ok := strings.CutSuffix(route, "/*"); ok {
+		return prefix + "/{path}"
+	}
+	// URLFormat отрезает расширение до роутинга, клиенты же зовут /openapi.json
+	if route == "/openapi" {
+		return "/openapi.json"
+	}
+
+	return route
+}
+
+// properties собирает поля схемы с учетом $ref и allOf.
+func properties(doc document, s schema) []string {
+	if s.Ref != "" {
+		return properties(doc, doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")])
+	}
+
+	var names []string
+	for _, part := range s.AllOf {
+		names = append(names, properties(doc, part)...)
+	}
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// jsonFields возвращает имена полей, под которыми encoding/json
+// выводит структуру, включая поля встроенных структур.
+func jsonFields(typ reflect.Type) []string {
+	var names []string
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -2,25 +2,54 @@ package response
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/Tbits007/url-shortener/pkg/api"
 	validator "github.com/go-playground/validator/v10"
 )
 
-type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-}
+// Формат ответов описан в pkg/api, чтобы его разделял клиент API.
+type (
+	Response   = api.Response
+	FieldError = api.FieldError
+)
+
+const (
+	StatusOK    = api.StatusOK
+	StatusError = api.StatusError
+)
 
 const (
-	StatusOK    = "OK"
-	StatusError = "Error"
+	CodeEmptyRequest        = api.CodeEmptyRequest
+	CodeInvalidJSON         = api.CodeInvalidJSON
+	CodeInvalidParameter    = api.CodeInvalidParameter
+	CodeUnauthorized        = api.CodeUnauthorized
+	CodeNotFound            = api.CodeNotFound
+	CodeNotYetAvailable     = api.CodeNotYetAvailable
+	CodeMethodNotAllowed    = api.CodeMethodNotAllowed
+	CodeAliasExists         = api.CodeAliasExists
+	CodeAliasReserved       = api.CodeAliasReserved
+	CodeUpdateConflict      = api.CodeUpdateConflict
+	CodeGone                = api.CodeGone
+	CodeVersionMismatch     = api.CodeVersionMismatch
+	CodeValidationFailed    = api.CodeValidationFailed
+	CodeUTMTemplateNotFound = api.CodeUTMTemplateNotFound
+	CodeRequestCanceled     = api.CodeRequestCanceled
+	CodeInternal            = api.CodeInternal
+	CodeStorageTimeout      = api.CodeStorageTimeout
+	CodeNotReady            = api.CodeNotReady
 )
 
-func Error(msg string) Response {
+// StatusClientClosedRequest - нестандартный код (как в nginx) для запросов,
+// которые клиент оборвал раньше, чем сервер успел ответить.
+const StatusClientClosedRequest = 499
+
+func Error(code, msg string) Response {
 	return Response{
 		Status: StatusError,
 		Error:  msg,
+		Code:   code,
 	}
 }
 
@@ -30,22 +59,71 @@ func OK() Response {
 	}
 }
 
+// NewValidator возвращает валидатор, который называет поля по тегам json,
+// чтобы в details были те же имена, что клиент отправил в запросе.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return v
+}
+
+// ValidationFailed собирает ответ 422 из ошибок отдельных полей.
+func ValidationFailed(details ...FieldError) Response {
+	msgs := make([]string, 0, len(details))
+	for _, d := range details {
+		msgs = append(msgs, d.Message)
+	}
+
+	return Response{
+		Status:  StatusError,
+		Error:   strings.Join(msgs, ", "),
+		Code:    CodeValidationFailed,
+		Details: details,
+	}
+}
+
 func ValidationError(errs validator.ValidationErrors) Response {
-	var errMsgs []string
+	return ValidationFailed(FieldErrors(errs)...)
+}
+
+// FieldErrors переводит ошибки валидатора в ошибки полей для details.
+func FieldErrors(errs validator.ValidationErrors) []FieldError {
+	details := make([]FieldError, 0, len(errs))
 
 	for _, err := range errs {
+		// Namespace начинается с имени структуры запроса: Request.targeting[0].url
+		_, field, _ := strings.Cut(err.Namespace(), ".")
+
+		var msg string
 		switch err.ActualTag() {
 		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
+			msg = fmt.Sprintf("field %s is a required field", field)
 		case "url":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not a valid URL", err.Field()))
+			msg = fmt.Sprintf("field %s is not a valid URL", field)
+		case "min":
+			msg = fmt.Sprintf("field %s must be at least %s", field, err.Param())
+		case "max":
+			msg = fmt.Sprintf("field %s must be at most %s", field, err.Param())
+		case "oneof":
+			msg = fmt.Sprintf("field %s must be one of: %s", field, err.Param())
 		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
+			msg = fmt.Sprintf("field %s is not valid", field)
 		}
-	}
 
-	return Response{
-		Status: StatusError,
-		Error:  strings.Join(errMsgs, ", "),
+		details = append(details, FieldError{
+			Field:   field,
+			Rule:    err.ActualTag(),
+			Param:   err.Param(),
+			Message: msg,
+		})
 	}
-}
\ No newline at end of file
+
+	return details
+}
@@ -0,0 +1,34 @@
+package basicauth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+// Check сверяет учетные данные со списком пользователей. Пароль
+// сравнивается за постоянное время и не выдается таймингом ответа.
+func Check(creds map[string]string, user, pass string) bool {
+	expected, ok := creds[user]
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(pass)) == 1
+}
+
+// Parse разбирает значение заголовка Authorization со схемой Basic,
+// как http.Request.BasicAuth. Нужен там, где запроса net/http нет (gRPC).
+func Parse(header string) (user, pass string, ok bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+
+	return strings.Cut(string(decoded), ":")
+}
@@ -0,0 +1,66 @@
+package basicauth
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		user   string
+		pass   string
+		ok     bool
+	}{
+		{
+			name:   "valid",
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:p:ss")),
+			user:   "admin",
+			pass:   "p:ss",
+			ok:     true,
+		},
+		{
+			name:   "scheme is case insensitive",
+			header: "basic " + base64.StdEncoding.EncodeToString([]byte("admin:secret")),
+			user:   "admin",
+			pass:   "secret",
+			ok:     true,
+		},
+		{
+			name:   "other scheme",
+			header: "Bearer token",
+		},
+		{
+			name:   "not base64",
+			header: "Basic !!!",
+		},
+		{
+			name:   "no colon",
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte("admin")),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, pass, ok := Parse(tc.header)
+
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.user, user)
+				assert.Equal(t, tc.pass, pass)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	creds := map[string]string{"admin": "secret"}
+
+	assert.True(t, Check(creds, "admin", "secret"))
+	assert.False(t, Check(creds, "admin", "wrong"))
+	assert.False(t, Check(creds, "guest", "secret"))
+	assert.False(t, Check(creds, "", ""))
+}
@@ -0,0 +1,97 @@
+// Package geo определяет страну посетителя без обращений к внешним сервисам:
+// по заголовку доверенного прокси или по локальной базе GeoIP в формате MMDB.
+package geo
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Resolver возвращает ISO 3166-1 alpha-2 код страны запроса.
+// Нулевой Resolver ничего не определяет и всегда возвращает пустую строку.
+type Resolver struct {
+	db     *maxminddb.Reader
+	header string
+}
+
+// record - общая для GeoLite2-Country, GeoLite2-City и DB-IP часть записи.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+func New(cfg config.Geo) (*Resolver, error) {
+	const op = "lib.geo.New"
+
+	r := &Resolver{header: cfg.CountryHeader}
+
+	if cfg.DatabasePath != "" {
+		db, err := maxminddb.Open(cfg.DatabasePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: open database: %w", op, err)
+		}
+		r.db = db
+	}
+
+	return r, nil
+}
+
+// Country возвращает код страны в верхнем регистре или пустую строку,
+// если страну определить не удалось. Заголовок прокси важнее базы.
+func (g *Resolver) Country(r *http.Request) string {
+	if g.header != "" {
+		if country := normalize(r.Header.Get(g.header)); country != "" {
+			return country
+		}
+	}
+
+	if g.db == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	var rec record
+	if err := g.db.Lookup(ip, &rec); err != nil {
+		return ""
+	}
+
+	return normalize(rec.Country.ISOCode)
+}
+
+// normalize отбрасывает все, что не похоже на код страны. Cloudflare
+// присылает XX для неизвестных адресов и T1 для Tor.
+func normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code == "XX" || code == "T1" {
+		return ""
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return ""
+		}
+	}
+
+	return code
+}
+
+func (g *Resolver) Close() error {
+	if g.db == nil {
+		return nil
+	}
+
+	return g.db.Close()
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_CountryHeader(t *testing.T) {
+	g, err := New(config.Geo{CountryHeader: "CF-IPCountry"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "country code", header: "de", want: "DE"},
+		{name: "unknown", header: "XX"},
+		{name: "tor", header: "T1"},
+		{name: "garbage", header: "Germany"},
+		{name: "missing"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("CF-IPCountry", tc.header)
+			}
+
+			assert.Equal(t, tc.want, g.Country(req))
+		})
+	}
+}
+
+func TestResolver_UntrustedHeaderIgnored(t *testing.T) {
+	g, err := New(config.Geo{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("CF-IPCountry", "DE")
+
+	assert.Empty(t, g.Country(req))
+}
+
+func TestNew_MissingDatabase(t *testing.T) {
+	_, err := New(config.Geo{DatabasePath: filepath.Join(t.TempDir(), "missing.mmdb")})
+	assert.Error(t, err)
+}
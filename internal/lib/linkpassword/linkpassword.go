@@ -0,0 +1,164 @@
+package linkpassword
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/ratelimit"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const cookiePrefix = "link_access_"
+
+// Hash возвращает bcrypt-хэш пароля ссылки для хранения в базе.
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("lib.linkpassword.Hash: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// Guard проверяет пароли защищенных ссылок, ограничивает перебор
+// и выдает подписанную куку, чтобы повторно пароль не спрашивать.
+type Guard struct {
+	secret []byte
+	ttl    time.Duration
+	// Попытки с одного адреса к ссылке и попытки к ссылке со всех адресов
+	limiter      *ratelimit.Limiter
+	aliasLimiter *ratelimit.Limiter
+	now          func() time.Time
+}
+
+// NewGuard создает Guard. Если секрет для подписи куки не задан,
+// генерируется случайный: куки перестанут действовать после рестарта
+// и не будут приниматься другими инстансами.
+func NewGuard(cfg config.LinkPassword) (*Guard, error) {
+	secret := []byte(cfg.CookieSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("lib.linkpassword.NewGuard: %w", err)
+		}
+	}
+
+	g := &Guard{
+		secret:  secret,
+		ttl:     cfg.CookieTTL,
+		limiter: ratelimit.New(cfg.MaxAttempts, cfg.AttemptWindow),
+		now:     time.Now,
+	}
+	if cfg.AliasMaxAttempts > 0 {
+		g.aliasLimiter = ratelimit.New(cfg.AliasMaxAttempts, cfg.AliasLockout)
+	}
+
+	return g, nil
+}
+
+// Allow учитывает попытку ввода пароля с адреса клиента для алиаса.
+// Кроме лимита по адресу действует общий лимит ссылки: исчерпав его,
+// перебор с многих адресов блокирует ввод пароля к ссылке для всех до
+// конца окна. Успешный ввод этот счетчик не сбрасывает, иначе владелец
+// пароля, сам того не зная, снимал бы блокировку для перебирающего.
+func (g *Guard) Allow(r *http.Request, alias string) (bool, time.Duration) {
+	if ok, retryAfter := g.limiter.Allow(attemptKey(r, alias)); !ok {
+		return false, retryAfter
+	}
+	if g.aliasLimiter == nil {
+		return true, 0
+	}
+
+	return g.aliasLimiter.Allow(alias)
+}
+
+// Check сравнивает пароль с хэшем и при успехе сбрасывает счетчик попыток.
+func (g *Guard) Check(r *http.Request, alias, hash, password string) bool {
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false
+	}
+
+	g.limiter.Reset(attemptKey(r, alias))
+
+	return true
+}
+
+// HasAccess проверяет куку доступа к ссылке.
+func (g *Guard) HasAccess(r *http.Request, alias, hash string) bool {
+	c, err := r.Cookie(cookieName(alias))
+	if err != nil {
+		return false
+	}
+
+	expiresRaw, sig, ok := strings.Cut(c.Value, ".")
+	if !ok {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(expiresRaw, 10, 64)
+	if err != nil || g.now().Unix() >= expires {
+		return false
+	}
+
+	expected := g.sign(alias, hash, expiresRaw)
+
+	return hmac.Equal([]byte(sig), []byte(expected))
+}
+
+// Grant выставляет подписанную куку доступа к ссылке.
+// Хэш пароля входит в подпись, поэтому смена пароля отзывает выданные куки.
+// Ссылку открывают по /{alias}, /{alias}/... и /{alias}+, а путь /{alias}
+// не покрывает предпросмотр: кука выдается на весь сайт, алиас - в имени.
+func (g *Guard) Grant(w http.ResponseWriter, r *http.Request, alias, hash string) {
+	expires := g.now().Add(g.ttl)
+	expiresRaw := strconv.FormatInt(expires.Unix(), 10)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName(alias),
+		Value:    expiresRaw + "." + g.sign(alias, hash, expiresRaw),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// cookieName кодирует алиас в имени куки: буквы не из ASCII, пробелы
+// и другие символы алиаса недопустимы в имени, и такую куку браузер
+// бы не получил.
+func cookieName(alias string) string {
+	return cookiePrefix + base64.RawURLEncoding.EncodeToString([]byte(alias))
+}
+
+func (g *Guard) sign(alias, hash, expires string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(alias + "\x00" + hash + "\x00" + expires))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// attemptKey - ключ лимита по адресу клиента. Адрес берется из
+// r.RemoteAddr, то есть это адрес TCP-соединения: сервис не подключает
+// middleware.RealIP, и за балансировщиком все клиенты делят его адрес.
+// Если RealIP добавить, RemoteAddr будет браться из X-Real-IP или
+// X-Forwarded-For, и прокси обязан перезаписывать эти заголовки: иначе
+// клиент подставит в них любой адрес и обойдет лимит. Общий лимит ссылки
+// от заголовков не зависит.
+func attemptKey(r *http.Request, alias string) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return alias + "|" + host
+}
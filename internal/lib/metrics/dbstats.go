@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	dbLabels = []string{"db"}
+
+	dbMaxOpenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "max_open_connections"),
+		"Maximum number of open connections to the database.", dbLabels, nil)
+	dbOpenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "open_connections"),
+		"The number of established connections both in use and idle.", dbLabels, nil)
+	dbInUseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "in_use_connections"),
+		"The number of connections currently in use.", dbLabels, nil)
+	dbIdleDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "idle_connections"),
+		"The number of idle connections.", dbLabels, nil)
+	dbWaitCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "wait_count_total"),
+		"The total number of connections waited for.", dbLabels, nil)
+	dbWaitDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "wait_duration_seconds_total"),
+		"The total time blocked waiting for a new connection.", dbLabels, nil)
+	dbMaxIdleClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "max_idle_closed_total"),
+		"The total number of connections closed due to SetMaxIdleConns.", dbLabels, nil)
+	dbMaxIdleTimeClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "max_idle_time_closed_total"),
+		"The total number of connections closed due to SetConnMaxIdleTime.", dbLabels, nil)
+	dbMaxLifetimeClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "max_lifetime_closed_total"),
+		"The total number of connections closed due to SetConnMaxLifetime.", dbLabels, nil)
+)
+
+type dbStatsCollector struct {
+	stats func() map[string]sql.DBStats
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbMaxOpenDesc
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+	ch <- dbMaxIdleClosedDesc
+	ch <- dbMaxIdleTimeClosedDesc
+	ch <- dbMaxLifetimeClosedDesc
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, s := range c.stats() {
+		collectDBStats(ch, name, s)
+	}
+}
+
+func collectDBStats(ch chan<- prometheus.Metric, name string, s sql.DBStats) {
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections), name)
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections), name)
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse), name)
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle), name)
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount), name)
+	ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds(), name)
+	ch <- prometheus.MustNewConstMetric(dbMaxIdleClosedDesc, prometheus.CounterValue, float64(s.MaxIdleClosed), name)
+	ch <- prometheus.MustNewConstMetric(dbMaxIdleTimeClosedDesc, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), name)
+	ch <- prometheus.MustNewConstMetric(dbMaxLifetimeClosedDesc, prometheus.CounterValue, float64(s.MaxLifetimeClosed), name)
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "url_shortener"
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route pattern, method and status class.",
+	}, []string{"route", "method", "status_class"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status class.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status_class"})
+
+	RedirectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Number of redirects served.",
+	})
+
+	ClicksDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clicks_dropped_total",
+		Help:      "Number of click events lost because the write queue was full or the write failed.",
+	})
+
+	LinksCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "links_created_total",
+		Help:      "Number of short links created.",
+	})
+
+	StorageQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Storage query latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	StorageQueryErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_query_errors_total",
+		Help:      "Number of failed storage queries by operation.",
+	}, []string{"operation"})
+)
+
+// Handler отдает метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// StatusClass сворачивает код ответа в класс вида "2xx",
+// чтобы не плодить временные ряды на каждый код.
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// ObserveStorageQuery записывает длительность запроса к хранилищу.
+// Вызывается через defer с указателем на возвращаемую ошибку.
+// Ожидаемые бизнес-ошибки (ссылка не найдена, алиас занят) сбоями не считаются.
+func ObserveStorageQuery(operation string, start time.Time, err *error) {
+	StorageQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+
+	if err == nil || *err == nil {
+		return
+	}
+	if storage.IsBusinessError(*err) {
+		return
+	}
+
+	StorageQueryErrorsTotal.WithLabelValues(operation).Inc()
+}
+
+// RegisterDBStats публикует статистику пулов соединений из sql.DB.Stats().
+// Ключ карты попадает в метку db и различает primary и реплики.
+func RegisterDBStats(stats func() map[string]sql.DBStats) {
+	prometheus.MustRegister(&dbStatsCollector{stats: stats})
+}
@@ -0,0 +1,159 @@
+// Package pages рисует HTML-страницы, которые видят посетители коротких
+// ссылок. Встроенные шаблоны можно заменить своими файлами через конфиг.
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+)
+
+//go:embed templates/*.html
+var defaults embed.FS
+
+type Pages struct {
+	preview      *template.Template
+	interstitial *template.Template
+	openGraph    *template.Template
+	notFound     *template.Template
+	gone         *template.Template
+}
+
+// PreviewData - данные страницы предпросмотра ссылки.
+type PreviewData struct {
+	Alias     string
+	Title     string
+	URL       string
+	CreatedAt time.Time
+	Owner     string
+	// Адрес назначения зависит от посетителя: у ссылки есть
+	// правила таргетинга или варианты A/B-теста.
+	Dynamic bool
+}
+
+// InterstitialData - данные страницы-предупреждения перед переходом.
+type InterstitialData struct {
+	Alias string
+	Title string
+	URL   string
+	Host  string
+}
+
+// OpenGraphData - разметка превью для краулеров соцсетей.
+// URL - адрес назначения: на него же уводит meta refresh.
+type OpenGraphData struct {
+	Title       string
+	Description string
+	Image       string
+	URL         string
+}
+
+// ErrorData - данные страницы ошибки.
+type ErrorData struct {
+	Status  int
+	Alias   string
+	Message string
+}
+
+// New загружает шаблоны: файл из конфига, если задан, иначе встроенный.
+func New(cfg config.Pages) (*Pages, error) {
+	const op = "lib.pages.New"
+
+	preview, err := load("preview.html", cfg.PreviewTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	interstitial, err := load("interstitial.html", cfg.InterstitialTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	openGraph, err := load("opengraph.html", cfg.OpenGraphTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	notFound, err := load("not_found.html", cfg.NotFoundTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	gone, err := load("gone.html", cfg.GoneTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Pages{
+		preview:      preview,
+		interstitial: interstitial,
+		openGraph:    openGraph,
+		notFound:     notFound,
+		gone:         gone,
+	}, nil
+}
+
+func load(name, path string) (*template.Template, error) {
+	if path == "" {
+		return template.ParseFS(defaults, "templates/"+name)
+	}
+
+	tmpl, err := template.ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+func (p *Pages) Preview(w http.ResponseWriter, status int, data PreviewData) error {
+	return render(w, p.preview, status, data)
+}
+
+func (p *Pages) Interstitial(w http.ResponseWriter, status int, data InterstitialData) error {
+	if data.Host == "" {
+		if u, err := url.Parse(data.URL); err == nil {
+			data.Host = u.Hostname()
+		}
+	}
+
+	return render(w, p.interstitial, status, data)
+}
+
+func (p *Pages) OpenGraph(w http.ResponseWriter, status int, data OpenGraphData) error {
+	return render(w, p.openGraph, status, data)
+}
+
+// Error рисует страницу ошибки: для 410 - "ссылка больше недоступна",
+// для остальных кодов - "ссылка не найдена".
+func (p *Pages) Error(w http.ResponseWriter, status int, data ErrorData) error {
+	data.Status = status
+
+	tmpl := p.notFound
+	if status == http.StatusGone {
+		tmpl = p.gone
+	}
+
+	return render(w, tmpl, status, data)
+}
+
+// render сначала выполняет шаблон в буфер: ошибка в пользовательском
+// шаблоне не должна оставить посетителя с обрезанной страницей.
+func render(w http.ResponseWriter, tmpl *template.Template, status int, data any) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("execute %s: %w", tmpl.Name(), err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
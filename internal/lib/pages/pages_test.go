@@ -0,0 +1,102 @@
+package pages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPages_Defaults(t *testing.T) {
+	p, err := New(config.Pages{})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	err = p.Preview(w, http.StatusOK, PreviewData{
+		Alias:     "docs",
+		Title:     "Docs <beta>",
+		URL:       "https://example.com/docs",
+		CreatedAt: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		Owner:     "admin",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "Docs &lt;beta&gt;")
+	assert.Contains(t, w.Body.String(), `href="https://example.com/docs"`)
+	assert.Contains(t, w.Body.String(), "14 Mar 2025")
+	assert.Contains(t, w.Body.String(), "admin")
+
+	w = httptest.NewRecorder()
+	err = p.Interstitial(w, http.StatusOK, InterstitialData{Alias: "docs", URL: "https://example.com/docs"})
+	require.NoError(t, err)
+	assert.Contains(t, w.Body.String(), "You are leaving to example.com")
+
+	w = httptest.NewRecorder()
+	err = p.OpenGraph(w, http.StatusOK, OpenGraphData{
+		Title:       `Spring "sale"`,
+		Description: "Up to 50% off",
+		Image:       "https://example.com/og.png",
+		URL:         "https://example.com/sale?utm_source=x&utm_medium=y",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, w.Body.String(), `<meta property="og:title" content="Spring &#34;sale&#34;">`)
+	assert.Contains(t, w.Body.String(), `<meta property="og:image" content="https://example.com/og.png">`)
+	assert.Contains(t, w.Body.String(), `<meta name="twitter:card" content="summary_large_image">`)
+	assert.Contains(t, w.Body.String(), `<meta property="og:url" content="https://example.com/sale?utm_source=x&amp;utm_medium=y">`)
+}
+
+func TestPages_Error(t *testing.T) {
+	p, err := New(config.Pages{})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, p.Error(w, http.StatusNotFound, ErrorData{Alias: "nope"}))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Link not found")
+
+	w = httptest.NewRecorder()
+	require.NoError(t, p.Error(w, http.StatusGone, ErrorData{Alias: "old"}))
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), "no longer available")
+
+	// Код "еще не доступна" задается в конфиге, страница - как у 404
+	w = httptest.NewRecorder()
+	require.NoError(t, p.Error(w, http.StatusTooEarly, ErrorData{Message: "coming soon"}))
+	assert.Equal(t, http.StatusTooEarly, w.Code)
+	assert.Contains(t, w.Body.String(), "coming soon")
+}
+
+func TestPages_Override(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preview.html")
+	require.NoError(t, os.WriteFile(path, []byte(`custom {{.Alias}} -> {{.URL}}`), 0o600))
+
+	p, err := New(config.Pages{PreviewTemplate: path})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, p.Preview(w, http.StatusOK, PreviewData{Alias: "docs", URL: "https://example.com/"}))
+	assert.Equal(t, "custom docs -> https://example.com/", w.Body.String())
+}
+
+func TestPages_BrokenTemplate(t *testing.T) {
+	_, err := New(config.Pages{InterstitialTemplate: filepath.Join(t.TempDir(), "missing.html")})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "preview.html")
+	require.NoError(t, os.WriteFile(path, []byte(`before {{.Missing}} after`), 0o600))
+
+	p, err := New(config.Pages{PreviewTemplate: path})
+	require.NoError(t, err)
+
+	// Ошибка выполнения не должна отдать посетителю половину страницы
+	w := httptest.NewRecorder()
+	assert.Error(t, p.Preview(w, http.StatusOK, PreviewData{}))
+	assert.Empty(t, w.Body.String())
+}
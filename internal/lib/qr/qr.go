@@ -0,0 +1,167 @@
+// Package qr рисует QR-коды в PNG и SVG без внешних сервисов.
+// Кодирование делает go-qrcode, отрисовка своя: библиотека не умеет SVG
+// и произвольное поле вокруг кода.
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Уровни коррекции ошибок: какую долю кода можно повредить без потери данных.
+const (
+	LevelLow      = "L" // ~7%
+	LevelMedium   = "M" // ~15%
+	LevelQuartile = "Q" // ~25%
+	LevelHigh     = "H" // ~30%
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	LevelLow:      qrcode.Low,
+	LevelMedium:   qrcode.Medium,
+	LevelQuartile: qrcode.High,
+	LevelHigh:     qrcode.Highest,
+}
+
+type Options struct {
+	// Ширина и высота картинки в пикселях.
+	Size int
+	// Уровень коррекции ошибок: L, M, Q или H.
+	Level string
+	// Поле вокруг кода в модулях (клетках). Стандарт требует не меньше 4.
+	Margin     int
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// modules возвращает матрицу кода вместе с полем: true - темный модуль.
+func modules(content string, opts Options) ([][]bool, error) {
+	level, ok := levels[opts.Level]
+	if !ok {
+		return nil, fmt.Errorf("unknown error correction level %q", opts.Level)
+	}
+
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	code.DisableBorder = true
+
+	bitmap := code.Bitmap()
+	n := len(bitmap) + 2*opts.Margin
+
+	grid := make([][]bool, n)
+	for y := range grid {
+		grid[y] = make([]bool, n)
+	}
+	for y, row := range bitmap {
+		copy(grid[y+opts.Margin][opts.Margin:], row)
+	}
+
+	return grid, nil
+}
+
+// PNG рисует код размером Size x Size. Модули целого размера в пикселях,
+// чтобы сканер не спотыкался о неровные клетки; остаток уходит в поле.
+func PNG(content string, opts Options) ([]byte, error) {
+	grid, err := modules(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(grid)
+	scale := opts.Size / n
+	if scale < 1 {
+		return nil, fmt.Errorf("size %d is too small for %d modules", opts.Size, n)
+	}
+	offset := (opts.Size - scale*n) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range grid {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SVG рисует код в координатах модулей; Size задает только размер по умолчанию,
+// векторная картинка масштабируется без потерь.
+func SVG(content string, opts Options) ([]byte, error) {
+	grid, err := modules(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(grid)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, n, n)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"%s/>`, n, n, hex(opts.Background), opacity(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="%s"%s d="`, hex(opts.Foreground), opacity(opts.Foreground))
+	for y, row := range grid {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
+
+// ParseColor разбирает цвет в hex: RGB, RRGGBB или RRGGBBAA, с # или без.
+func ParseColor(s string) (color.NRGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+
+	switch len(s) {
+	case 3:
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]}) + "ff"
+	case 6:
+		s += "ff"
+	case 8:
+	default:
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+func hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.NRGBA) string {
+	if c.A == 0xff {
+		return ""
+	}
+
+	return fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+}
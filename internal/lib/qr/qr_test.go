@@ -0,0 +1,84 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func TestPNG(t *testing.T) {
+	data, err := PNG("https://sho.rt/abc", Options{Size: 300, Level: LevelMedium, Margin: 4, Foreground: black, Background: white})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// Версия 2 (25 модулей) + поле 4+4 = 33 модуля по 9 пикселей,
+	// лишние 3 пикселя делятся между краями
+	assert.Equal(t, white, color.NRGBAModel.Convert(img.At(0, 0)))
+	assert.Equal(t, black, color.NRGBAModel.Convert(img.At(1+4*9, 1+4*9)), "finder pattern corner")
+}
+
+func TestPNG_TooSmall(t *testing.T) {
+	_, err := PNG("https://sho.rt/abc", Options{Size: 20, Level: LevelMedium, Margin: 4, Foreground: black, Background: white})
+	assert.Error(t, err)
+}
+
+func TestSVG(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0x80}
+
+	data, err := SVG("https://sho.rt/abc", Options{Size: 512, Level: LevelHigh, Margin: 2, Foreground: red, Background: white})
+	require.NoError(t, err)
+
+	svg := string(data)
+	assert.Contains(t, svg, `width="512" height="512"`)
+	assert.Contains(t, svg, `fill="#ffffff"`)
+	assert.Contains(t, svg, `fill="#ff0000" fill-opacity="0.502"`)
+	// Первый темный модуль - угол поискового узора сразу за полем
+	assert.Contains(t, svg, `d="M2 2h1v1h-1z`)
+}
+
+func TestUnknownLevel(t *testing.T) {
+	_, err := SVG("x", Options{Size: 100, Level: "X"})
+	assert.Error(t, err)
+}
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{in: "000", want: black},
+		{in: "#ffffff", want: white},
+		{in: "1a2b3c", want: color.NRGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}},
+		{in: "ff000080", want: color.NRGBA{R: 0xff, A: 0x80}},
+		{in: "red", wantErr: true},
+		{in: "12345", wantErr: true},
+		{in: "gggggg", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseColor(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter ограничивает число попыток по ключу в фиксированном окне.
+// Состояние хранится в памяти процесса.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+type entry struct {
+	count int
+	reset time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Allow учитывает попытку по ключу. Если лимит исчерпан, возвращает false
+// и время, через которое можно повторить.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	e, ok := l.entries[key]
+	if !ok || !now.Before(e.reset) {
+		e = &entry{reset: now.Add(l.window)}
+		l.entries[key] = e
+	}
+
+	if e.count >= l.limit {
+		return false, e.reset.Sub(now)
+	}
+
+	e.count++
+
+	return true, 0
+}
+
+// Reset забывает попытки по ключу, например после успешного ввода пароля.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// sweep удаляет истекшие окна, чтобы карта не росла бесконечно.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+
+	for key, e := range l.entries {
+		if !now.Before(e.reset) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+
+	ok, retry := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, retry)
+
+	// Другие ключи считаются независимо
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// После окончания окна попытки снова разрешены
+	now = now.Add(time.Minute)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+
+	l.Reset("a")
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+}
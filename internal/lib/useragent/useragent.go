@@ -0,0 +1,98 @@
+// Package useragent грубо классифицирует клиента по заголовку User-Agent:
+// операционная система, класс устройства и признак бота. Этого достаточно,
+// чтобы развести посетителей по магазинам приложений; точный разбор версий
+// браузеров сюда сознательно не входит.
+package useragent
+
+import "strings"
+
+// Операционные системы.
+const (
+	OSiOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+)
+
+// Классы устройств.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// Info - результат разбора User-Agent. Пустая OS - система не распознана.
+type Info struct {
+	OS     string
+	Device string
+	Bot    bool
+	// Краулер мессенджера или соцсети, который строит превью ссылки.
+	// Такой клиент всегда и Bot.
+	Social bool
+}
+
+// Подстроки, по которым узнаются краулеры, превью мессенджеров и HTTP-клиенты.
+var botMarkers = []string{
+	"bot", "crawler", "spider", "slurp", "crawl",
+	"facebookexternalhit", "embedly", "preview", "whatsapp",
+	"curl/", "wget/", "python-requests", "go-http-client", "okhttp", "headless",
+}
+
+// Подстроки краулеров, которые читают Open Graph разметку для превью.
+var socialMarkers = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "linkedinbot",
+	"slackbot", "slack-imgproxy", "discordbot", "telegrambot", "whatsapp",
+	"skypeuripreview", "pinterestbot", "redditbot", "vkshare",
+	"embedly", "iframely",
+}
+
+// Parse разбирает строку User-Agent. Порядок проверок важен: UA Android
+// содержит "Linux", а UA iOS - "like Mac OS X".
+func Parse(ua string) Info {
+	s := strings.ToLower(ua)
+
+	info := Info{Device: DeviceDesktop}
+
+	switch {
+	case strings.Contains(s, "iphone"), strings.Contains(s, "ipod"):
+		info.OS, info.Device = OSiOS, DeviceMobile
+	case strings.Contains(s, "ipad"):
+		info.OS, info.Device = OSiOS, DeviceTablet
+	case strings.Contains(s, "android"):
+		info.OS = OSAndroid
+		// Планшеты на Android не пишут "Mobile" в User-Agent
+		info.Device = DeviceTablet
+		if strings.Contains(s, "mobile") {
+			info.Device = DeviceMobile
+		}
+	case strings.Contains(s, "windows"):
+		info.OS = OSWindows
+	case strings.Contains(s, "cros"):
+		info.OS = OSChromeOS
+	case strings.Contains(s, "macintosh"), strings.Contains(s, "mac os x"):
+		info.OS = OSMacOS
+	case strings.Contains(s, "linux"):
+		info.OS = OSLinux
+	}
+
+	if ua == "" {
+		// Браузеры всегда присылают User-Agent, пустой бывает только у скриптов
+		info.Bot = true
+	}
+	for _, marker := range botMarkers {
+		if strings.Contains(s, marker) {
+			info.Bot = true
+			break
+		}
+	}
+	for _, marker := range socialMarkers {
+		if strings.Contains(s, marker) {
+			info.Bot, info.Social = true, true
+			break
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "time"
+
+// Click - переход по короткой ссылке: куда ушел посетитель и кто он.
+type Click struct {
+	Alias string
+	At    time.Time
+	// Итоговый адрес редиректа, с учетом сработавшего правила таргетинга.
+	URL string
+	// Имя варианта A/B-теста; пусто, если у ссылки нет вариантов
+	// или сработало правило таргетинга.
+	Variant string
+
+	Country  string
+	Language string
+	OS       string
+	Device   string
+	Bot      bool
+}
+
+// ClickStats - сводка переходов по ссылке.
+type ClickStats struct {
+	Total int
+	// Число переходов по имени варианта A/B-теста.
+	Variants map[string]int
+}
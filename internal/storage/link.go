@@ -0,0 +1,96 @@
+package storage
+
+import (
+    "time"
+
+    "github.com/Tbits007/url-shortener/pkg/api"
+)
+
+// Link - сохраненная короткая ссылка со всеми ее настройками.
+type Link struct {
+    Alias string
+    URL   string
+    // Название ссылки для страниц предпросмотра и предупреждения.
+    Title string
+
+    // Кто и когда создал ссылку. Владелец - пользователь basic auth,
+    // CreatedAt проставляет база.
+    Owner     string
+    CreatedAt time.Time
+    // Растет при каждом изменении ссылки. UpdateURL меняет ссылку, только
+    // если версия в базе совпадает с Version, иначе ErrVersionConflict.
+    Version int
+
+    // HTTP-код редиректа (301, 302, 303, 307, 308).
+    // 0 - использовать код по умолчанию из конфига.
+    RedirectType int
+
+    // Дописывать ли query-параметры запроса к адресу назначения
+    // и как поступать, если параметр уже есть в адресе назначения.
+    ForwardQuery  bool
+    QueryConflict string
+    // Дописывать ли остаток пути /{alias}/rest/of/path к адресу назначения.
+    ForwardPath bool
+
+    // UTM-метки, которые дописываются к адресу назначения при редиректе.
+    // Поля UTM перекрывают одноименные поля шаблона UTMTemplate.
+    UTM         UTM
+    UTMTemplate string
+    // Метки шаблона UTMTemplate. Хранилище читает их вместе со ссылкой,
+    // чтобы редирект не ходил в базу за шаблоном отдельным запросом.
+    TemplateUTM UTM
+
+    // bcrypt-хэш пароля. Пустая строка - ссылка открыта для всех.
+    PasswordHash string
+
+    // Сколько раз ссылку можно открыть. 0 - без ограничений.
+    MaxClicks       int
+    ClicksRemaining int
+
+    // Окно, в котором ссылка ведет на URL. Нулевое время - без ограничения.
+    // До начала окна посетителя отправляют на PendingURL, после конца -
+    // на ExpiredURL; если они не заданы, отдается ошибка.
+    ActiveFrom  time.Time
+    ActiveUntil time.Time
+    PendingURL  string
+    ExpiredURL  string
+
+    // Правила выбора адреса по клиенту. Первое подошедшее правило
+    // (в порядке Priority) заменяет URL; если не подошло ни одно, ведем на URL.
+    Targeting []TargetRule
+
+    // Варианты адреса для A/B-теста. Если заданы, посетитель, не попавший
+    // ни под одно правило таргетинга, уходит на один из них вместо URL.
+    Variants []Variant
+
+    // Всегда показывать страницу "вы переходите на ..." вместо редиректа.
+    Interstitial bool
+
+    // Превью ссылки в мессенджерах и соцсетях.
+    OG OpenGraph
+}
+
+// Типы, которые хранятся в ссылке как есть и совпадают с форматом API.
+// Хранятся в базе как JSON, поэтому теги json - часть формата хранения.
+type (
+    OpenGraph  = api.OpenGraph
+    Variant    = api.Variant
+    TargetRule = api.TargetRule
+    UTM        = api.UTM
+)
+
+// ListFilter - одна страница списка ссылок. Ссылки упорядочены по алиасу,
+// страница начинается сразу после After.
+type ListFilter struct {
+    // Только ссылки этого владельца; пусто - все ссылки.
+    Owner string
+    After string
+    Limit int
+}
+
+// Политики разрешения конфликтов при переносе query-параметров.
+const (
+    QueryConflictKeep     = "keep"     // оставить значение из адреса назначения
+    QueryConflictOverride = "override" // заменить значением из запроса
+    QueryConflictAppend   = "append"   // оставить оба значения
+)
@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/lib/pq"
+)
+
+// RecordClick ставит событие перехода в очередь записи и не ждет базу.
+// Если очередь полна, событие теряется и возвращается storage.ErrClickQueueFull.
+func (s *Storage) RecordClick(_ context.Context, click storage.Click) error {
+	const op = "storage.postgres.RecordClick"
+
+	if err := s.clicks.add(click); err != nil {
+		metrics.ClicksDroppedTotal.Inc()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// recordClicks пишет пачку событий одним запросом. Вызывается из clickWriter.
+// Колонки передаются массивами, поэтому число параметров не зависит от
+// размера пачки. Клики удаленных ссылок пропускаются: иначе внешний ключ
+// на url отклонил бы всю пачку вместе с кликами других ссылок.
+func (s *Storage) recordClicks(ctx context.Context, clicks []storage.Click) (err error) {
+	const op = "storage.postgres.recordClicks"
+
+	ctx, span := tracing.Tracer().Start(ctx, op)
+	defer tracing.EndSpan(span, &err)
+	defer metrics.ObserveStorageQuery("RecordClicks", time.Now(), &err)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `
+    INSERT INTO click(alias, clicked_at, url, variant, country, language, os, device, bot)
+    SELECT v.alias, v.clicked_at, v.url, v.variant, v.country, v.language, v.os, v.device, v.bot
+    FROM unnest($1::text[], $2::timestamptz[], $3::text[], $4::text[], $5::text[],
+        $6::text[], $7::text[], $8::text[], $9::bool[])
+        AS v(alias, clicked_at, url, variant, country, language, os, device, bot)
+    WHERE EXISTS (SELECT 1 FROM url u WHERE u.alias = v.alias)`
+
+	var (
+		aliases, at, urls, variants   []string
+		countries, languages, systems []string
+		devices                       []string
+		bots                          []bool
+	)
+	for _, click := range clicks {
+		aliases = append(aliases, click.Alias)
+		at = append(at, click.At.Format(time.RFC3339Nano))
+		urls = append(urls, click.URL)
+		variants = append(variants, click.Variant)
+		countries = append(countries, click.Country)
+		languages = append(languages, click.Language)
+		systems = append(systems, click.OS)
+		devices = append(devices, click.Device)
+		bots = append(bots, click.Bot)
+	}
+	args := []any{
+		pq.Array(aliases), pq.Array(at), pq.Array(urls), pq.Array(variants), pq.Array(countries),
+		pq.Array(languages), pq.Array(systems), pq.Array(devices), pq.Array(bots),
+	}
+
+	_, err = s.db.ExecContext(ctx, query, args...)
+	// Ссылку удалили между проверкой EXISTS и вставкой: повторный запрос
+	// ее уже не увидит
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" { // 23503 - foreign_key_violation
+		_, err = s.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
+	}
+
+	return nil
+}
+
+var errClickWriterClosed = errors.New("click writer is closed")
+
+// maxClickBatch ограничивает пачку сверху: один огромный INSERT дольше
+// держит соединение и при ошибке теряет больше событий.
+const maxClickBatch = 1000
+
+// clickWriter пишет события переходов в фоне. Редирект не должен ждать
+// базу ради статистики: событие кладется в очередь ограниченного размера
+// и пишется пачкой вместе с соседними. Если база не успевает и очередь
+// полна, новые события теряются - это лучше, чем задерживать посетителя.
+type clickWriter struct {
+	write    func(ctx context.Context, clicks []storage.Click) error
+	batch    int
+	interval time.Duration
+
+	// mu защищает queue от записи после закрытия
+	mu     sync.RWMutex
+	closed bool
+	queue  chan storage.Click
+	done   chan struct{}
+}
+
+func newClickWriter(
+	write func(ctx context.Context, clicks []storage.Click) error,
+	size, batch int,
+	interval time.Duration,
+) *clickWriter {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	w := &clickWriter{
+		write:    write,
+		batch:    min(max(batch, 1), maxClickBatch),
+		interval: interval,
+		queue:    make(chan storage.Click, max(size, 1)),
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// add ставит событие в очередь, не блокируясь.
+func (w *clickWriter) add(click storage.Click) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return errClickWriterClosed
+	}
+
+	select {
+	case w.queue <- click:
+		return nil
+	default:
+		return storage.ErrClickQueueFull
+	}
+}
+
+// run собирает пачки и пишет их, когда пачка заполнена или прошел interval.
+func (w *clickWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]storage.Click, 0, w.batch)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Ошибку записи считает ObserveStorageQuery, повторять пачку
+		// некуда: очередь за это время только выросла бы
+		if err := w.write(context.Background(), batch); err != nil {
+			metrics.ClicksDroppedTotal.Add(float64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case click, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= w.batch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// close перестает принимать события и дожидается записи уже принятых.
+func (w *clickWriter) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+}
+
+// GetClickStats считает переходы по ссылке, в том числе по вариантам
+// A/B-теста. Для несуществующей ссылки возвращает storage.ErrURLNotFound.
+func (s *Storage) GetClickStats(ctx context.Context, alias string) (_ storage.ClickStats, err error) {
+	const op = "storage.postgres.GetClickStats"
+
+	ctx, span := tracing.Tracer().Start(ctx, op)
+	defer tracing.EndSpan(span, &err)
+	defer metrics.ObserveStorageQuery("GetClickStats", time.Now(), &err)
+
+	// LEFT JOIN отличает ссылку без переходов (одна строка с NULL)
+	// от несуществующей ссылки (ни одной строки)
+	query := `
+    SELECT c.variant, COUNT(c.id)
+    FROM url u LEFT JOIN click c ON c.alias = u.alias
+    WHERE u.alias = $1
+    GROUP BY c.variant`
+
+	var stats storage.ClickStats
+	err = s.read(ctx, urlKey(alias), func(ctx context.Context, db *sql.DB) error {
+		stats = storage.ClickStats{Variants: map[string]int{}}
+		found := false
+
+		rows, err := db.QueryContext(ctx, query, alias)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				variant sql.NullString
+				count   int
+			)
+			if err := rows.Scan(&variant, &count); err != nil {
+				return err
+			}
+
+			found = true
+			stats.Total += count
+			if variant.String != "" {
+				stats.Variants[variant.String] = count
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if !found {
+			// Ни одной строки: ссылки нет, а не переходов
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ClickStats{}, fmt.Errorf("%s: url not found: %w", op, storage.ErrURLNotFound)
+		}
+		return storage.ClickStats{}, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return stats, nil
+}
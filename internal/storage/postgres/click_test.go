@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batches запоминает пачки, которые clickWriter отдал на запись.
+type batches struct {
+	mu  sync.Mutex
+	got [][]string
+}
+
+func (b *batches) write(_ context.Context, clicks []storage.Click) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	aliases := make([]string, 0, len(clicks))
+	for _, click := range clicks {
+		aliases = append(aliases, click.Alias)
+	}
+	b.got = append(b.got, aliases)
+
+	return nil
+}
+
+func (b *batches) snapshot() [][]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([][]string(nil), b.got...)
+}
+
+func TestClickWriter_Batches(t *testing.T) {
+	var b batches
+	w := newClickWriter(b.write, 10, 2, time.Hour)
+
+	for _, alias := range []string{"a", "b", "c"} {
+		require.NoError(t, w.add(storage.Click{Alias: alias}))
+	}
+
+	// Полная пачка пишется сразу, не дожидаясь интервала
+	require.Eventually(t, func() bool { return len(b.snapshot()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b"}}, b.snapshot())
+
+	// Остаток дописывается при закрытии
+	w.close()
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, b.snapshot())
+
+	assert.ErrorIs(t, w.add(storage.Click{Alias: "d"}), errClickWriterClosed)
+}
+
+func TestClickWriter_FlushInterval(t *testing.T) {
+	var b batches
+	w := newClickWriter(b.write, 10, 100, 10*time.Millisecond)
+	defer w.close()
+
+	require.NoError(t, w.add(storage.Click{Alias: "a"}))
+
+	require.Eventually(t, func() bool { return len(b.snapshot()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]string{{"a"}}, b.snapshot())
+}
+
+func TestClickWriter_QueueFull(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	write := func(context.Context, []storage.Click) error {
+		once.Do(func() { close(started) })
+		<-release
+		return nil
+	}
+
+	w := newClickWriter(write, 1, 1, time.Hour)
+
+	// Первое событие забирает запись и зависает на ней, второе ждет в очереди
+	require.NoError(t, w.add(storage.Click{Alias: "a"}))
+	<-started
+	require.NoError(t, w.add(storage.Click{Alias: "b"}))
+
+	// add не ждет медленную базу, а отказывает сразу
+	assert.ErrorIs(t, w.add(storage.Click{Alias: "c"}), storage.ErrClickQueueFull)
+
+	close(release)
+	w.close()
+}
+
+func TestClickWriter_BatchCap(t *testing.T) {
+	var b batches
+	w := newClickWriter(b.write, 10, 100_000, time.Hour)
+	defer w.close()
+
+	assert.Equal(t, maxClickBatch, w.batch)
+}
+
+// TestStorage_RecordClicksDeletedLink проверяет запрос на настоящей базе:
+// клик удаленной ссылки не должен ронять запись остальных кликов пачки.
+func TestStorage_RecordClicksDeletedLink(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	kept, deleted := "click-kept-"+randomSuffix(), "click-deleted-"+randomSuffix()
+	for _, alias := range []string{kept, deleted} {
+		require.NoError(t, s.SaveURL(ctx, storage.Link{Alias: alias, URL: "https://example.com"}))
+	}
+	t.Cleanup(func() { _ = s.DeleteURL(ctx, kept) })
+
+	// Ссылку удалили, пока ее клик ждал в очереди
+	require.NoError(t, s.DeleteURL(ctx, deleted))
+
+	err := s.recordClicks(ctx, []storage.Click{
+		{Alias: kept, At: time.Now(), URL: "https://example.com"},
+		{Alias: deleted, At: time.Now(), URL: "https://example.com"},
+		{Alias: kept, At: time.Now(), URL: "https://example.com"},
+	})
+	require.NoError(t, err)
+
+	stats, err := s.GetClickStats(ctx, kept)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Total)
+}
@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS redirect_type SMALLINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS active_until TIMESTAMPTZ;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS pending_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS expired_url TEXT NOT NULL DEFAULT '';
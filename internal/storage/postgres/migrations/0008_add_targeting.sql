@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS targeting JSONB NOT NULL DEFAULT '[]';
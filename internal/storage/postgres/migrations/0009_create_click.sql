@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS click(
+    id BIGSERIAL PRIMARY KEY,
+    alias TEXT NOT NULL REFERENCES url(alias) ON DELETE CASCADE,
+    clicked_at TIMESTAMPTZ NOT NULL,
+    url TEXT NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
+    os TEXT NOT NULL DEFAULT '',
+    device TEXT NOT NULL DEFAULT '',
+    bot BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_click_alias_clicked_at ON click(alias, clicked_at);
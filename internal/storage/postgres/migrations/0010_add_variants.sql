@@ -0,0 +1,2 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE click ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS interstitial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Дата создания старых ссылок неизвестна и остается NULL,
+-- иначе все они разом стали бы "новыми"
+ALTER TABLE url ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+ALTER TABLE url ALTER COLUMN created_at SET DEFAULT now();
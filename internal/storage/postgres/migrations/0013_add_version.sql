@@ -0,0 +1 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -1,80 +1,518 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
 	"github.com/Tbits007/url-shortener/internal/storage"
 	"github.com/lib/pq"
 )
 	
 
 type Storage struct {
-	db *sql.DB
+	db       *sql.DB
+	timeouts config.QueryTimeouts
+
+	replicas []*replica
+	next     atomic.Uint32
+	done     chan struct{}
+	// Недавно созданные ссылки и шаблоны: их чтение может уйти в primary
+	recent *recentWrites
+	clicks *clickWriter
 }
 
-func New(connStr string) (*Storage, error) {
+func New(ctx context.Context, cfg config.Postgres) (*Storage, error) {
     const op = "storage.postgres.New"
 
-    db, err := sql.Open("postgres", connStr) 
+    db, err := openDB(cfg.ConnString(), cfg)
     if err != nil {
         return nil, fmt.Errorf("%s: %w", op, err)
     }
 
-    query := `
-    DROP TABLE IF EXISTS url;
-
-    CREATE TABLE IF NOT EXISTS url(
-        id SERIAL PRIMARY KEY,
-        alias TEXT NOT NULL UNIQUE,
-        url TEXT NOT NULL
-    );
+    err = waitForDB(ctx, db, cfg.ConnectAttempts, cfg.ConnectRetryBackoff)
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("%s: %w", op, err)
+    }
 
-    CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
-    `
+    err = migrate(ctx, db)
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("%s: %w", op, err)
+    }
 
-    _, err = db.Exec(query)
+    replicas, err := openReplicas(ctx, cfg)
     if err != nil {
+        db.Close()
         return nil, fmt.Errorf("%s: %w", op, err)
     }
 
-    return &Storage{db: db}, nil
+    s := &Storage{
+        db:       db,
+        timeouts: cfg.QueryTimeouts,
+        replicas: replicas,
+        done:     make(chan struct{}),
+        recent:   newRecentWrites(cfg.ReplicaLagWindow, len(replicas) > 0),
+    }
+    s.clicks = newClickWriter(s.recordClicks, cfg.ClickQueueSize, cfg.ClickBatchSize, cfg.ClickFlushInterval)
+
+    if len(replicas) > 0 {
+        go s.checkReplicas(cfg.ReplicaCheckInterval, cfg.QueryTimeouts.Read)
+    }
+
+    return s, nil
+}
+
+func openDB(connStr string, cfg config.Postgres) (*sql.DB, error) {
+    db, err := sql.Open("postgres", connStr)
+    if err != nil {
+        return nil, err
+    }
+
+    db.SetMaxOpenConns(cfg.MaxOpenConns)
+    db.SetMaxIdleConns(cfg.MaxIdleConns)
+    db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+    db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+    return db, nil
 }
 
-func (s *Storage) SaveURL(urlToSave, alias string) error {
+// waitForDB пингует базу, пока она не ответит. При старте в docker-compose
+// или k8s база часто поднимается позже сервиса, поэтому падать сразу не стоит.
+// Пауза между попытками удваивается.
+func waitForDB(ctx context.Context, db *sql.DB, attempts int, backoff time.Duration) error {
+    if attempts < 1 {
+        attempts = 1
+    }
+
+    var err error
+    for i := 0; i < attempts; i++ {
+        if err = db.PingContext(ctx); err == nil {
+            return nil
+        }
+
+        if i == attempts-1 {
+            break
+        }
+
+        select {
+        case <-ctx.Done():
+            return fmt.Errorf("ping: %w", ctx.Err())
+        case <-time.After(backoff << i):
+        }
+    }
+
+    return fmt.Errorf("ping after %d attempts: %w", attempts, err)
+}
+
+func (s *Storage) SaveURL(ctx context.Context, link storage.Link) (err error) {
     const op = "storage.postgres.SaveURL"
 
-    query := `INSERT INTO url(url,alias) values($1,$2)`
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("SaveURL", time.Now(), &err)
+
+    ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+    defer cancel()
+
+    query := `
+    INSERT INTO url(
+        url, alias, redirect_type, forward_query, query_conflict, forward_path,
+        utm_source, utm_medium, utm_campaign, utm_term, utm_content, utm_template,
+        password_hash, max_clicks, clicks_remaining,
+        active_from, active_until, pending_url, expired_url, targeting, variants,
+        title, owner, interstitial, og_title, og_description, og_image
+    )
+    VALUES(
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $14,
+        $15, $16, $17, $18, $19, $20,
+        $21, $22, $23, $24, $25, $26
+    )`
+
+    targeting, err := jsonArray(link.Targeting)
+    if err != nil {
+        return fmt.Errorf("%s: marshal targeting: %w", op, err)
+    }
+    variants, err := jsonArray(link.Variants)
+    if err != nil {
+        return fmt.Errorf("%s: marshal variants: %w", op, err)
+    }
     
-    _, err := s.db.Exec(query, urlToSave, alias)
+    _, err = s.db.ExecContext(ctx, query,
+        link.URL, link.Alias, link.RedirectType,
+        link.ForwardQuery, link.QueryConflict, link.ForwardPath,
+        link.UTM.Source, link.UTM.Medium, link.UTM.Campaign, link.UTM.Term, link.UTM.Content, link.UTMTemplate,
+        link.PasswordHash, link.MaxClicks,
+        nullTime(link.ActiveFrom), nullTime(link.ActiveUntil), link.PendingURL, link.ExpiredURL, targeting, variants,
+        link.Title, link.Owner, link.Interstitial, link.OG.Title, link.OG.Description, link.OG.Image,
+    )
     if err != nil {
         if pgErr, ok := err.(*pq.Error); ok {
             if pgErr.Code == "23505" { // 23505 - это код ошибки unique_violation в PostgreSQL
                 return fmt.Errorf("%s: %w", op, storage.ErrURLExists)
             }
+            if pgErr.Code == "23503" { // 23503 - foreign_key_violation: нет такого UTM-шаблона
+                return fmt.Errorf("%s: %w", op, storage.ErrUTMTemplateNotFound)
+            }
         }
-        return fmt.Errorf("%s: execute query: %w", op, err)
+        return fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
     }
 
+    s.recent.add(urlKey(link.Alias))
+
     return nil
 }
 
-func (s *Storage) GetURL(alias string) (string, error) {
+func (s *Storage) GetURL(ctx context.Context, alias string) (_ storage.Link, err error) {
     const op = "storage.postgres.GetURL"
 
-    query := `SELECT url FROM url WHERE alias = $1`
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("GetURL", time.Now(), &err)
 
-    row := s.db.QueryRow(query, alias)
+    query := `SELECT ` + linkColumns + ` FROM ` + linkTables + ` WHERE alias = $1`
 
-    var res string 
-    err := row.Scan(&res)
+    var res storage.Link
+    err = s.read(ctx, urlKey(alias), func(ctx context.Context, db *sql.DB) error {
+        return scanLink(db.QueryRowContext(ctx, query, alias), &res)
+    })
     if err != nil {
         if errors.Is(err, sql.ErrNoRows) {
-            return "", fmt.Errorf("%s: url not found: %w", op, storage.ErrURLNotFound)
+            return storage.Link{}, fmt.Errorf("%s: url not found: %w", op, storage.ErrURLNotFound)
         }
-        return "", fmt.Errorf("%s: execute query:%w", op, err)
+        return storage.Link{}, fmt.Errorf("%s: execute query: %w", op, err)
     } 
 
     return res, nil 
 }
+
+// ListURLs возвращает страницу ссылок по фильтру. Постраничный обход
+// по алиасу не пропускает и не повторяет ссылки, даже если между
+// запросами страниц ссылки создаются или удаляются.
+func (s *Storage) ListURLs(ctx context.Context, filter storage.ListFilter) (_ []storage.Link, err error) {
+    const op = "storage.postgres.ListURLs"
+
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("ListURLs", time.Now(), &err)
+
+    query := `SELECT ` + linkColumns + ` FROM ` + linkTables + `
+    WHERE alias > $1 AND ($2 = '' OR owner = $2)
+    ORDER BY alias
+    LIMIT $3`
+
+    var links []storage.Link
+    err = s.read(ctx, "", func(ctx context.Context, db *sql.DB) error {
+        links = nil
+
+        rows, err := db.QueryContext(ctx, query, filter.After, filter.Owner, filter.Limit)
+        if err != nil {
+            return err
+        }
+        defer rows.Close()
+
+        for rows.Next() {
+            var link storage.Link
+            if err := scanLink(rows, &link); err != nil {
+                return err
+            }
+            links = append(links, link)
+        }
+
+        return rows.Err()
+    })
+    if err != nil {
+        return nil, fmt.Errorf("%s: execute query: %w", op, err)
+    }
+
+    return links, nil
+}
+
+// UpdateURL заменяет настройки существующей ссылки. Алиас, владелец и дата
+// создания не меняются. При смене max_clicks уже сделанные переходы
+// сохраняются: остаток считается от нового лимита. Изменение проходит,
+// только если ссылку не успели изменить после чтения link.Version,
+// иначе возвращается storage.ErrVersionConflict.
+func (s *Storage) UpdateURL(ctx context.Context, link storage.Link) (err error) {
+    const op = "storage.postgres.UpdateURL"
+
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("UpdateURL", time.Now(), &err)
+
+    ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+    defer cancel()
+
+    // В SET справа видны значения строки до изменения
+    query := `
+    UPDATE url SET
+        url = $2, redirect_type = $3, forward_query = $4, query_conflict = $5, forward_path = $6,
+        utm_source = $7, utm_medium = $8, utm_campaign = $9, utm_term = $10, utm_content = $11,
+        utm_template = NULLIF($12, ''), password_hash = $13,
+        clicks_remaining = GREATEST($14 - (max_clicks - clicks_remaining), 0), max_clicks = $14,
+        active_from = $15, active_until = $16, pending_url = $17, expired_url = $18,
+        targeting = $19, variants = $20,
+        title = $21, interstitial = $22, og_title = $23, og_description = $24, og_image = $25,
+        version = version + 1
+    WHERE alias = $1 AND version = $26`
+
+    targeting, err := jsonArray(link.Targeting)
+    if err != nil {
+        return fmt.Errorf("%s: marshal targeting: %w", op, err)
+    }
+    variants, err := jsonArray(link.Variants)
+    if err != nil {
+        return fmt.Errorf("%s: marshal variants: %w", op, err)
+    }
+
+    res, err := s.db.ExecContext(ctx, query,
+        link.Alias, link.URL, link.RedirectType,
+        link.ForwardQuery, link.QueryConflict, link.ForwardPath,
+        link.UTM.Source, link.UTM.Medium, link.UTM.Campaign, link.UTM.Term, link.UTM.Content, link.UTMTemplate,
+        link.PasswordHash, link.MaxClicks,
+        nullTime(link.ActiveFrom), nullTime(link.ActiveUntil), link.PendingURL, link.ExpiredURL, targeting, variants,
+        link.Title, link.Interstitial, link.OG.Title, link.OG.Description, link.OG.Image,
+        link.Version,
+    )
+    if err != nil {
+        if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23503" {
+            return fmt.Errorf("%s: %w", op, storage.ErrUTMTemplateNotFound)
+        }
+        return fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
+    }
+
+    err = affectedOne(op, res)
+    if errors.Is(err, storage.ErrURLNotFound) {
+        // Ни одной строки: ссылки нет или ее версия уже другая
+        var exists bool
+        err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM url WHERE alias = $1)`, link.Alias).Scan(&exists)
+        if err != nil {
+            return fmt.Errorf("%s: check existence: %w", op, queryErr(ctx, err))
+        }
+        if exists {
+            return fmt.Errorf("%s: %w", op, storage.ErrVersionConflict)
+        }
+    }
+    if err != nil {
+        return err
+    }
+    s.recent.add(urlKey(link.Alias))
+
+    return nil
+}
+
+// DeleteURL удаляет ссылку вместе с ее статистикой переходов.
+func (s *Storage) DeleteURL(ctx context.Context, alias string) (err error) {
+    const op = "storage.postgres.DeleteURL"
+
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("DeleteURL", time.Now(), &err)
+
+    ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+    defer cancel()
+
+    // Переходы удаляются каскадом по внешнему ключу click.alias
+    res, err := s.db.ExecContext(ctx, `DELETE FROM url WHERE alias = $1`, alias)
+    if err != nil {
+        return fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
+    }
+
+    if err := affectedOne(op, res); err != nil {
+        return err
+    }
+    s.recent.add(urlKey(alias))
+
+    return nil
+}
+
+// affectedOne превращает изменение нуля строк в ErrURLNotFound.
+func affectedOne(op string, res sql.Result) error {
+    n, err := res.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("%s: rows affected: %w", op, err)
+    }
+    if n == 0 {
+        return fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+    }
+
+    return nil
+}
+
+// ConsumeClick списывает один переход у ссылки с ограничением по числу кликов.
+// Проверка и списание - один условный UPDATE, поэтому одновременные посетители
+// не могут превысить лимит. Всегда выполняется на primary.
+func (s *Storage) ConsumeClick(ctx context.Context, alias string) (_ int, err error) {
+    const op = "storage.postgres.ConsumeClick"
+
+    ctx, span := tracing.Tracer().Start(ctx, op)
+    defer tracing.EndSpan(span, &err)
+    defer metrics.ObserveStorageQuery("ConsumeClick", time.Now(), &err)
+
+    ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+    defer cancel()
+
+    query := `
+    UPDATE url SET clicks_remaining = clicks_remaining - 1
+    WHERE alias = $1 AND max_clicks > 0 AND clicks_remaining > 0
+    RETURNING clicks_remaining`
+
+    var remaining int
+    err = s.db.QueryRowContext(ctx, query, alias).Scan(&remaining)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return 0, fmt.Errorf("%s: %w", op, storage.ErrClicksExhausted)
+        }
+        return 0, fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
+    }
+
+    return remaining, nil
+}
+
+const linkColumns = `alias, url, redirect_type, forward_query, query_conflict, forward_path,
+    utm_source, utm_medium, utm_campaign, utm_term, utm_content, COALESCE(utm_template, ''),
+    password_hash, max_clicks, clicks_remaining,
+    active_from, active_until, pending_url, expired_url, targeting, variants,
+    title, owner, created_at, version, interstitial, og_title, og_description, og_image,
+    COALESCE(t.source, ''), COALESCE(t.medium, ''), COALESCE(t.campaign, ''),
+    COALESCE(t.term, ''), COALESCE(t.content, '')`
+
+// linkTables - ссылка вместе с ее UTM-шаблоном. Имена колонок url
+// и utm_template не пересекаются, поэтому в linkColumns и условиях
+// запросов колонки url указаны без таблицы.
+const linkTables = `url LEFT JOIN utm_template t ON t.name = url.utm_template`
+
+// scanLink читает строку, выбранную по linkColumns.
+func scanLink(row interface{ Scan(dest ...any) error }, link *storage.Link) error {
+    var activeFrom, activeUntil, createdAt sql.NullTime
+    var targeting, variants []byte
+
+    err := row.Scan(
+        &link.Alias, &link.URL, &link.RedirectType,
+        &link.ForwardQuery, &link.QueryConflict, &link.ForwardPath,
+        &link.UTM.Source, &link.UTM.Medium, &link.UTM.Campaign, &link.UTM.Term, &link.UTM.Content, &link.UTMTemplate,
+        &link.PasswordHash, &link.MaxClicks, &link.ClicksRemaining,
+        &activeFrom, &activeUntil, &link.PendingURL, &link.ExpiredURL, &targeting, &variants,
+        &link.Title, &link.Owner, &createdAt, &link.Version, &link.Interstitial,
+        &link.OG.Title, &link.OG.Description, &link.OG.Image,
+        &link.TemplateUTM.Source, &link.TemplateUTM.Medium, &link.TemplateUTM.Campaign,
+        &link.TemplateUTM.Term, &link.TemplateUTM.Content,
+    )
+    if err != nil {
+        return err
+    }
+
+    link.ActiveFrom = activeFrom.Time
+    link.ActiveUntil = activeUntil.Time
+    link.CreatedAt = createdAt.Time
+
+    if err := json.Unmarshal(targeting, &link.Targeting); err != nil {
+        return fmt.Errorf("unmarshal targeting: %w", err)
+    }
+    if len(link.Targeting) == 0 {
+        link.Targeting = nil
+    }
+
+    if err := json.Unmarshal(variants, &link.Variants); err != nil {
+        return fmt.Errorf("unmarshal variants: %w", err)
+    }
+    if len(link.Variants) == 0 {
+        link.Variants = nil
+    }
+
+    return nil
+}
+
+// jsonArray сохраняет пустой список как [], а не null:
+// у JSONB-колонок есть DEFAULT '[]' и читать единообразные данные проще.
+func jsonArray[T any](items []T) ([]byte, error) {
+    if items == nil {
+        items = []T{}
+    }
+
+    return json.Marshal(items)
+}
+
+// nullTime сохраняет нулевое время как NULL.
+func nullTime(t time.Time) sql.NullTime {
+    return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// queryErr приводит ошибку отмененного запроса к ошибке контекста.
+// lib/pq при отмене отправляет серверу cancel request и возвращает
+// свою ошибку query_canceled, по которой хендлер не поймет, что случилось.
+// Тайм-аут на стороне сервера (statement_timeout) считается дедлайном.
+func queryErr(ctx context.Context, err error) error {
+    if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+        return fmt.Errorf("%w: %w", ctxErr, err)
+    }
+
+    // 57014 - query_canceled: контекст жив, значит сработал statement_timeout
+    var pgErr *pq.Error
+    if errors.As(err, &pgErr) && pgErr.Code == "57014" {
+        return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+    }
+
+    return err
+}
+
+// Ping проверяет, что база данных доступна.
+func (s *Storage) Ping(ctx context.Context) error {
+    const op = "storage.postgres.Ping"
+
+    if err := s.db.PingContext(ctx); err != nil {
+        return fmt.Errorf("%s: %w", op, err)
+    }
+
+    return nil
+}
+
+// CheckMigrations проверяет, что в базе применены все миграции,
+// известные этой версии сервиса.
+func (s *Storage) CheckMigrations(ctx context.Context) error {
+    const op = "storage.postgres.CheckMigrations"
+
+    migrations, err := loadMigrations()
+    if err != nil {
+        return fmt.Errorf("%s: %w", op, err)
+    }
+
+    var version int
+    err = s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+    if err != nil {
+        return fmt.Errorf("%s: execute query: %w", op, err)
+    }
+
+    if latest := migrations[len(migrations)-1].version; version < latest {
+        return fmt.Errorf("%s: schema version %d, want %d: %w", op, version, latest, storage.ErrMigrationsPending)
+    }
+
+    return nil
+}
+
+// PoolStats возвращает статистику пулов соединений: primary и replica-N.
+func (s *Storage) PoolStats() map[string]sql.DBStats {
+    stats := map[string]sql.DBStats{"primary": s.db.Stats()}
+    for i, r := range s.replicas {
+        stats[fmt.Sprintf("replica-%d", i)] = r.db.Stats()
+    }
+
+    return stats
+}
+
+func (s *Storage) Close() error {
+    // Переходы, принятые до остановки, пишутся, пока база еще открыта
+    s.clicks.close()
+    close(s.done)
+    closeReplicas(s.replicas)
+
+    return s.db.Close()
+}
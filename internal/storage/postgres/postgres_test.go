@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/lib/random"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage подключается к базе из TEST_POSTGRES_DSN. Без нее тесты,
+// которым нужен настоящий Postgres, пропускаются.
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	s, err := New(context.Background(), config.Postgres{
+		DSN:             dsn,
+		ConnectAttempts: 1,
+		QueryTimeouts: config.QueryTimeouts{
+			Read:  time.Second,
+			Write: 3 * time.Second,
+		},
+		ClickQueueSize:     100,
+		ClickBatchSize:     10,
+		ClickFlushInterval: time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+func randomSuffix() string {
+	return random.NewRandomString(8)
+}
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/lib/pq"
+)
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+func openReplicas(ctx context.Context, cfg config.Postgres) ([]*replica, error) {
+	replicas := make([]*replica, 0, len(cfg.Replicas))
+
+	for i, dsn := range cfg.Replicas {
+		db, err := openDB(cfg.ReplicaConnString(dsn), cfg)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+
+		r := &replica{db: db}
+		// Недоступная при старте реплика не мешает запуску:
+		// чтения уйдут в primary, а проверка вернет ее в ротацию позже
+		r.healthy.Store(db.PingContext(ctx) == nil)
+
+		replicas = append(replicas, r)
+	}
+
+	return replicas, nil
+}
+
+func closeReplicas(replicas []*replica) {
+	for _, r := range replicas {
+		r.db.Close()
+	}
+}
+
+// checkReplicas периодически пингует реплики и включает/выключает их в ротации.
+func (s *Storage) checkReplicas(interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.recent.prune()
+			for _, r := range s.replicas {
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				r.healthy.Store(r.db.PingContext(ctx) == nil)
+				cancel()
+			}
+		}
+	}
+}
+
+// pickReplica выбирает следующую здоровую реплику по кругу.
+// nil означает, что читать нужно из primary.
+func (s *Storage) pickReplica(ctx context.Context) *replica {
+	if len(s.replicas) == 0 || storage.UsePrimary(ctx) {
+		return nil
+	}
+
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		r := s.replicas[(int(start)+i)%len(s.replicas)]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// read выполняет чтение на реплике. Ключи, записанные в пределах окна
+// отставания репликации, читаются сразу из primary целиком: после
+// создания или изменения ссылки клиент не должен увидеть ни 404, ни
+// старую версию. Гарантия действует в пределах инстанса: recentWrites
+// знает только о записях этого процесса, поэтому клиентам, которым
+// важно читать свои записи через другие инстансы, нужен storage.WithPrimary.
+// Если реплика недоступна, чтение повторяется на primary.
+// Каждая попытка получает свой таймаут чтения.
+func (s *Storage) read(ctx context.Context, key string, query func(ctx context.Context, db *sql.DB) error) error {
+	r := s.pickReplica(ctx)
+	if r == nil || s.recent.has(key) {
+		return s.attempt(ctx, s.db, query)
+	}
+
+	err := s.attempt(ctx, r.db, query)
+	if err == nil || ctx.Err() != nil || !unavailable(err) {
+		return err
+	}
+
+	r.healthy.Store(false)
+
+	return s.attempt(ctx, s.db, query)
+}
+
+func (s *Storage) attempt(ctx context.Context, db *sql.DB, query func(ctx context.Context, db *sql.DB) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if err := query(ctx, db); err != nil {
+		return queryErr(ctx, err)
+	}
+
+	return nil
+}
+
+// unavailable отличает отказ самой базы (обрыв соединения, рестарт)
+// от ошибки запроса, которую повтор не исправит. Таймаут - признак
+// медленного запроса, а не отказа: из-за одного тяжелого запроса
+// реплика не должна выпадать из ротации.
+func unavailable(err error) bool {
+	// Проверяется до net.Error: ошибка дедлайна контекста реализует и его
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code.Class() {
+		case "08", "53": // connection_exception, insufficient_resources
+			return true
+		}
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+	}
+
+	return false
+}
+
+// recentWrites помнит ключи недавних записей этого процесса, которые
+// реплики могли еще не получить. Без реплик не хранит ничего.
+type recentWrites struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+func newRecentWrites(window time.Duration, enabled bool) *recentWrites {
+	w := &recentWrites{window: window}
+	if enabled {
+		w.keys = make(map[string]time.Time)
+	}
+
+	return w
+}
+
+func (w *recentWrites) add(key string) {
+	if w.keys == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.keys[key] = time.Now()
+}
+
+func (w *recentWrites) has(key string) bool {
+	if w.keys == nil || key == "" {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	at, ok := w.keys[key]
+	return ok && time.Since(at) < w.window
+}
+
+// prune забывает записи старше окна. Вызывается из checkReplicas.
+func (w *recentWrites) prune() {
+	if w.keys == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for key, at := range w.keys {
+		if time.Since(at) >= w.window {
+			delete(w.keys, key)
+		}
+	}
+}
+
+// Ключи recentWrites для ссылок и шаблонов UTM.
+func urlKey(alias string) string { return "url:" + alias }
+func utmKey(name string) string  { return "utm:" + name }
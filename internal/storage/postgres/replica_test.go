@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicaStorage собирает Storage с primary и репликами без подключения:
+// sql.Open не ходит в базу, а запросы в тестах подменяются.
+func newReplicaStorage(t *testing.T, replicas int) *Storage {
+	t.Helper()
+
+	open := func() *sql.DB {
+		db, err := sql.Open("postgres", "postgres://localhost/test")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+
+	s := &Storage{
+		db:       open(),
+		timeouts: config.QueryTimeouts{Read: time.Second, Write: time.Second},
+		recent:   newRecentWrites(time.Minute, replicas > 0),
+	}
+	for range replicas {
+		r := &replica{db: open()}
+		r.healthy.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+
+	return s
+}
+
+// stubQuery отвечает ошибкой по базе и запоминает, в какие базы ходил запрос.
+type stubQuery struct {
+	s     *Storage
+	errs  map[*sql.DB]error
+	calls []string
+}
+
+func (q *stubQuery) query(_ context.Context, db *sql.DB) error {
+	q.calls = append(q.calls, q.name(db))
+	return q.errs[db]
+}
+
+func (q *stubQuery) name(db *sql.DB) string {
+	if db == q.s.db {
+		return "primary"
+	}
+	for i, r := range q.s.replicas {
+		if r.db == db {
+			return fmt.Sprintf("replica-%d", i)
+		}
+	}
+	return "unknown"
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name           string
+		replicaErr     error
+		primaryErr     error
+		recentKey      bool
+		primaryCtx     bool
+		expectedCalls  []string
+		expectedErr    error
+		replicaHealthy bool
+	}{
+		{
+			name:           "served by replica",
+			expectedCalls:  []string{"replica-0"},
+			replicaHealthy: true,
+		},
+		{
+			name:           "not found on replica is not retried",
+			replicaErr:     sql.ErrNoRows,
+			expectedCalls:  []string{"replica-0"},
+			expectedErr:    sql.ErrNoRows,
+			replicaHealthy: true,
+		},
+		{
+			name:           "recent write goes to primary",
+			recentKey:      true,
+			expectedCalls:  []string{"primary"},
+			replicaHealthy: true,
+		},
+		{
+			name:           "primary requested by context",
+			primaryCtx:     true,
+			expectedCalls:  []string{"primary"},
+			replicaHealthy: true,
+		},
+		{
+			name:           "unavailable replica falls back to primary",
+			replicaErr:     driver.ErrBadConn,
+			expectedCalls:  []string{"replica-0", "primary"},
+			replicaHealthy: false,
+		},
+		{
+			name:           "primary error after fallback",
+			replicaErr:     io.EOF,
+			primaryErr:     sql.ErrNoRows,
+			expectedCalls:  []string{"replica-0", "primary"},
+			expectedErr:    sql.ErrNoRows,
+			replicaHealthy: false,
+		},
+		{
+			name:           "timeout keeps replica in rotation",
+			replicaErr:     context.DeadlineExceeded,
+			expectedCalls:  []string{"replica-0"},
+			expectedErr:    context.DeadlineExceeded,
+			replicaHealthy: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newReplicaStorage(t, 1)
+			q := &stubQuery{s: s, errs: map[*sql.DB]error{
+				s.replicas[0].db: tc.replicaErr,
+				s.db:             tc.primaryErr,
+			}}
+
+			if tc.recentKey {
+				s.recent.add(urlKey("docs"))
+			}
+			ctx := context.Background()
+			if tc.primaryCtx {
+				ctx = storage.WithPrimary(ctx)
+			}
+
+			err := s.read(ctx, urlKey("docs"), q.query)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCalls, q.calls)
+			assert.Equal(t, tc.replicaHealthy, s.replicas[0].healthy.Load())
+		})
+	}
+}
+
+func TestRead_NoReplicas(t *testing.T) {
+	s := newReplicaStorage(t, 0)
+	q := &stubQuery{s: s}
+
+	require.NoError(t, s.read(context.Background(), urlKey("docs"), q.query))
+	assert.Equal(t, []string{"primary"}, q.calls)
+}
+
+func TestPickReplica(t *testing.T) {
+	s := newReplicaStorage(t, 3)
+	s.replicas[1].healthy.Store(false)
+
+	// По кругу, пропуская нездоровую реплику
+	picked := map[*replica]int{}
+	for range 6 {
+		picked[s.pickReplica(context.Background())]++
+	}
+	assert.Equal(t, map[*replica]int{s.replicas[0]: 2, s.replicas[2]: 4}, picked)
+
+	assert.Nil(t, s.pickReplica(storage.WithPrimary(context.Background())))
+
+	for _, r := range s.replicas {
+		r.healthy.Store(false)
+	}
+	assert.Nil(t, s.pickReplica(context.Background()))
+}
+
+func TestUnavailable(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "bad connection", err: driver.ErrBadConn, expected: true},
+		{name: "connection done", err: sql.ErrConnDone, expected: true},
+		{name: "eof", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), expected: true},
+		{name: "connection exception", err: &pq.Error{Code: "08006"}, expected: true},
+		{name: "too many connections", err: &pq.Error{Code: "53300"}, expected: true},
+		{name: "admin shutdown", err: &pq.Error{Code: "57P01"}, expected: true},
+		{name: "deadline", err: fmt.Errorf("%w: %w", context.DeadlineExceeded, &pq.Error{Code: "57014"}), expected: false},
+		{name: "canceled", err: context.Canceled, expected: false},
+		{name: "not found", err: sql.ErrNoRows, expected: false},
+		{name: "syntax error", err: &pq.Error{Code: "42601"}, expected: false},
+		{name: "other", err: errors.New("boom"), expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, unavailable(tc.err))
+		})
+	}
+}
+
+func TestRecentWrites(t *testing.T) {
+	w := newRecentWrites(50*time.Millisecond, true)
+
+	w.add(urlKey("docs"))
+	assert.True(t, w.has(urlKey("docs")))
+	assert.False(t, w.has(urlKey("blog")))
+	assert.False(t, w.has(utmKey("docs")))
+	assert.False(t, w.has(""))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, w.has(urlKey("docs")))
+
+	w.prune()
+	assert.Empty(t, w.keys)
+}
+
+func TestRecentWrites_Disabled(t *testing.T) {
+	w := newRecentWrites(time.Minute, false)
+
+	w.add(urlKey("docs"))
+	w.prune()
+
+	assert.False(t, w.has(urlKey("docs")))
+}
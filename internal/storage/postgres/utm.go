@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/lib/metrics"
+	"github.com/Tbits007/url-shortener/internal/lib/tracing"
+	"github.com/Tbits007/url-shortener/internal/storage"
+)
+
+// SaveUTMTemplate создает шаблон UTM-меток или заменяет существующий.
+func (s *Storage) SaveUTMTemplate(ctx context.Context, name string, utm storage.UTM) (err error) {
+	const op = "storage.postgres.SaveUTMTemplate"
+
+	ctx, span := tracing.Tracer().Start(ctx, op)
+	defer tracing.EndSpan(span, &err)
+	defer metrics.ObserveStorageQuery("SaveUTMTemplate", time.Now(), &err)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	query := `
+    INSERT INTO utm_template(name, source, medium, campaign, term, content)
+    VALUES($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (name) DO UPDATE SET
+        source = EXCLUDED.source,
+        medium = EXCLUDED.medium,
+        campaign = EXCLUDED.campaign,
+        term = EXCLUDED.term,
+        content = EXCLUDED.content`
+
+	_, err = s.db.ExecContext(ctx, query, name, utm.Source, utm.Medium, utm.Campaign, utm.Term, utm.Content)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, queryErr(ctx, err))
+	}
+
+	s.recent.add(utmKey(name))
+
+	return nil
+}
+
+func (s *Storage) GetUTMTemplate(ctx context.Context, name string) (_ storage.UTM, err error) {
+	const op = "storage.postgres.GetUTMTemplate"
+
+	ctx, span := tracing.Tracer().Start(ctx, op)
+	defer tracing.EndSpan(span, &err)
+	defer metrics.ObserveStorageQuery("GetUTMTemplate", time.Now(), &err)
+
+	query := `SELECT source, medium, campaign, term, content FROM utm_template WHERE name = $1`
+
+	var res storage.UTM
+	err = s.read(ctx, utmKey(name), func(ctx context.Context, db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, name).Scan(&res.Source, &res.Medium, &res.Campaign, &res.Term, &res.Content)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.UTM{}, fmt.Errorf("%s: %w", op, storage.ErrUTMTemplateNotFound)
+		}
+		return storage.UTM{}, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return res, nil
+}
@@ -1,8 +1,45 @@
 package storage
 
-import "errors"
+import (
+    "context"
+    "errors"
+
+    "github.com/Tbits007/url-shortener/pkg/api"
+)
 
 var (
-    ErrURLNotFound = errors.New("url not found")
-    ErrURLExists   = errors.New("url exists")
-)
\ No newline at end of file
+    ErrURLNotFound = api.ErrURLNotFound
+    ErrURLExists   = api.ErrURLExists
+
+    ErrUTMTemplateNotFound = api.ErrUTMTemplateNotFound
+    ErrClicksExhausted     = errors.New("clicks exhausted")
+    ErrClickQueueFull      = errors.New("click queue is full")
+    ErrVersionConflict     = errors.New("version conflict")
+
+    ErrMigrationsPending = errors.New("migrations pending")
+)
+
+// IsBusinessError сообщает, что ошибка - ожидаемый результат операции
+// (нет такой ссылки, алиас занят), а не сбой хранилища.
+// Такие ошибки не считаются в метриках и трейсах как отказы.
+func IsBusinessError(err error) bool {
+    return errors.Is(err, ErrURLNotFound) ||
+        errors.Is(err, ErrURLExists) ||
+        errors.Is(err, ErrUTMTemplateNotFound) ||
+        errors.Is(err, ErrClicksExhausted) ||
+        errors.Is(err, ErrVersionConflict)
+}
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст так, что чтения пойдут в primary, минуя реплики.
+// Нужно там, где клиент должен сразу увидеть собственную запись.
+func WithPrimary(ctx context.Context) context.Context {
+    return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary сообщает, требуется ли читать из primary.
+func UsePrimary(ctx context.Context) bool {
+    v, _ := ctx.Value(primaryKey{}).(bool)
+    return v
+}
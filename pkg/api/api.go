@@ -0,0 +1,50 @@
+// Package api описывает формат запросов и ответов HTTP API сервиса.
+// Пакет не зависит от internal и не имеет побочных эффектов при импорте,
+// поэтому его используют и обработчики сервера, и клиент из pkg/client.
+package api
+
+// Response - общая часть всех ответов API.
+type Response struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Стабильный машиночитаемый код ошибки; текст Error может меняться.
+	Code string `json:"code,omitempty"`
+	// Ошибки по отдельным полям запроса, если он не прошел проверку.
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError - ошибка в одном поле запроса. Field - путь к полю в JSON
+// запроса (targeting[0].url), Rule - нарушенное правило (required, url, max...).
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+const (
+	StatusOK    = "OK"
+	StatusError = "Error"
+)
+
+// Коды ошибок. Клиенты опираются на них, поэтому менять существующие нельзя.
+const (
+	CodeEmptyRequest        = "empty_request"          // 400: пустое тело запроса
+	CodeInvalidJSON         = "invalid_json"           // 400: тело не разбирается как JSON
+	CodeInvalidParameter    = "invalid_parameter"      // 400: неверный параметр пути или query
+	CodeUnauthorized        = "unauthorized"           // 401: нет или неверные учетные данные
+	CodeNotFound            = "not_found"              // 404: ссылки или шаблона нет
+	CodeNotYetAvailable     = "not_yet_available"      // код из конфига: окно ссылки еще не началось
+	CodeMethodNotAllowed    = "method_not_allowed"     // 405
+	CodeAliasExists         = "alias_exists"           // 409: алиас уже занят ссылкой
+	CodeAliasReserved       = "alias_reserved"         // 409: алиас занят служебным маршрутом
+	CodeUpdateConflict      = "update_conflict"        // 409: ссылку одновременно меняют другие запросы
+	CodeGone                = "gone"                   // 410: ссылка истекла или исчерпала клики
+	CodeVersionMismatch     = "version_mismatch"       // 412: If-Match не совпал с текущей версией ссылки
+	CodeValidationFailed    = "validation_failed"      // 422: запрос не прошел проверку, см. details
+	CodeUTMTemplateNotFound = "utm_template_not_found" // 422: ссылка ссылается на несуществующий шаблон
+	CodeRequestCanceled     = "request_canceled"       // 499: клиент не дождался ответа
+	CodeInternal            = "internal_error"         // 500
+	CodeStorageTimeout      = "storage_timeout"        // 503: база не ответила вовремя
+	CodeNotReady            = "not_ready"              // 503: сервис не готов принимать трафик
+)
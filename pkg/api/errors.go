@@ -0,0 +1,12 @@
+package api
+
+import "errors"
+
+// Ошибки, общие для хранилища и клиента: storage возвращает их же,
+// поэтому errors.Is работает одинаково на сервере и в клиенте.
+var (
+	ErrURLNotFound = errors.New("url not found")
+	ErrURLExists   = errors.New("url exists")
+
+	ErrUTMTemplateNotFound = errors.New("utm template not found")
+)
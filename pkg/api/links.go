@@ -0,0 +1,146 @@
+package api
+
+import "time"
+
+// CreateRequest - тело запроса на создание ссылки.
+type CreateRequest struct {
+	URL   string `json:"url" validate:"required,url"`
+	Alias string `json:"alias,omitempty"`
+	// Название для страниц предпросмотра и предупреждения о переходе
+	Title string `json:"title,omitempty" validate:"max=200"`
+	// 301/308 - постоянные, 302/303/307 - временные. Не задан - берется из конфига
+	RedirectType int `json:"redirect_type,omitempty" validate:"omitempty,oneof=301 302 303 307 308"`
+	// Переносить query-параметры запроса в адрес назначения; при конфликте
+	// keep оставляет значение ссылки, override - из запроса, append - оба
+	ForwardQuery  bool   `json:"forward_query,omitempty"`
+	QueryConflict string `json:"query_conflict,omitempty" validate:"omitempty,oneof=keep override append"`
+	// Дописывать остаток пути /{alias}/rest/of/path к адресу назначения
+	ForwardPath bool `json:"forward_path,omitempty"`
+	// UTM-метки, которые добавятся к адресу при редиректе. Можно задать
+	// поля явно, сослаться на шаблон или совместить: явные поля важнее
+	UTM         UTM    `json:"utm"`
+	UTMTemplate string `json:"utm_template,omitempty"`
+	// Пароль для открытия ссылки. Хранится только bcrypt-хэш;
+	// bcrypt учитывает не больше 72 байт
+	Password string `json:"password,omitempty" validate:"omitempty,min=4,max=72"`
+	// Сколько раз ссылку можно открыть, после чего она отвечает 410 Gone
+	MaxClicks int `json:"max_clicks,omitempty" validate:"omitempty,min=1"`
+	// Окно активности ссылки (RFC 3339). До начала окна посетителя уводит
+	// на pending_url, после конца - на expired_url, если они заданы
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	PendingURL  string     `json:"pending_url,omitempty" validate:"omitempty,url"`
+	ExpiredURL  string     `json:"expired_url,omitempty" validate:"omitempty,url"`
+	// Правила выбора адреса по ОС, классу устройства, признаку бота,
+	// стране и языку. Если ни одно не подошло, посетитель уходит на url
+	Targeting []TargetRule `json:"targeting,omitempty" validate:"omitempty,max=20,dive"`
+	// Варианты адреса для A/B-теста с весами. Посетитель закрепляется
+	// за вариантом; правила targeting проверяются раньше вариантов
+	Variants []Variant `json:"variants,omitempty" validate:"omitempty,min=2,max=10,unique=Name,dive"`
+	// Показывать перед переходом страницу "вы переходите на ..."
+	Interstitial bool `json:"interstitial,omitempty"`
+	// Заголовок, описание и картинка для превью в мессенджерах и соцсетях
+	OG OpenGraph `json:"og"`
+}
+
+type CreateResponse struct {
+	Response
+	Alias string `json:"alias"`
+}
+
+// Link - ссылка так, как ее видит клиент API: без хэша пароля
+// и с пустыми полями вместо нулевых значений.
+type Link struct {
+	Response
+	Alias        string `json:"alias"`
+	URL          string `json:"url"`
+	Title        string `json:"title,omitempty"`
+	RedirectType int    `json:"redirect_type,omitempty"`
+
+	Owner     string     `json:"owner,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	ForwardQuery  bool   `json:"forward_query,omitempty"`
+	QueryConflict string `json:"query_conflict,omitempty"`
+	ForwardPath   bool   `json:"forward_path,omitempty"`
+
+	UTM         *UTM   `json:"utm,omitempty"`
+	UTMTemplate string `json:"utm_template,omitempty"`
+
+	PasswordProtected bool `json:"password_protected,omitempty"`
+
+	MaxClicks       int  `json:"max_clicks,omitempty"`
+	ClicksRemaining *int `json:"clicks_remaining,omitempty"`
+
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	PendingURL  string     `json:"pending_url,omitempty"`
+	ExpiredURL  string     `json:"expired_url,omitempty"`
+
+	Targeting []TargetRule `json:"targeting,omitempty"`
+	Variants  []Variant    `json:"variants,omitempty"`
+
+	Interstitial bool `json:"interstitial,omitempty"`
+
+	OG *OpenGraph `json:"og,omitempty"`
+}
+
+// Stats - число переходов по ссылке и по вариантам A/B-теста.
+type Stats struct {
+	Response
+	Alias    string         `json:"alias"`
+	Clicks   int            `json:"clicks"`
+	Variants []VariantStats `json:"variants,omitempty"`
+}
+
+type VariantStats struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+	Clicks int    `json:"clicks"`
+}
+
+// OpenGraph - разметка, которую получают краулеры соцсетей вместо редиректа.
+// Пустая разметка - краулеры редиректятся, как и все.
+type OpenGraph struct {
+	Title       string `json:"title,omitempty" validate:"max=200"`
+	Description string `json:"description,omitempty" validate:"max=500"`
+	Image       string `json:"image,omitempty" validate:"omitempty,url"`
+}
+
+// Variant - один из адресов A/B-теста. Доля посетителей варианта
+// равна его весу, деленному на сумму весов всех вариантов.
+// Хранится в базе как JSON, как и TargetRule.
+type Variant struct {
+	Name   string `json:"name" validate:"required,max=64"`
+	URL    string `json:"url" validate:"required,url"`
+	Weight int    `json:"weight" validate:"required,min=1,max=1000"`
+}
+
+// TargetRule - правило таргетинга. Пустое условие не проверяется,
+// в списке достаточно совпадения с любым значением.
+// Хранится в базе как JSON, поэтому теги json - часть формата хранения.
+type TargetRule struct {
+	// Меньшее значение проверяется раньше; при равных - порядок в списке.
+	Priority int `json:"priority,omitempty"`
+
+	OS     []string `json:"os,omitempty" validate:"omitempty,dive,oneof=ios android windows macos linux chromeos"`
+	Device []string `json:"device,omitempty" validate:"omitempty,dive,oneof=mobile tablet desktop"`
+	// true - только краулеры и превью, false - только люди, не задано - все.
+	Bot *bool `json:"bot,omitempty"`
+
+	// Страна посетителя (ISO 3166-1 alpha-2, в верхнем регистре) и основной
+	// язык из Accept-Language. Язык "pt" подходит и для "pt-BR".
+	Country  []string `json:"country,omitempty" validate:"omitempty,dive,iso3166_1_alpha2"`
+	Language []string `json:"language,omitempty" validate:"omitempty,dive,bcp47_language_tag"`
+
+	URL string `json:"url" validate:"required,url"`
+}
+
+// LinkList - страница списка ссылок, упорядоченного по алиасу.
+// NextPageToken пуст на последней странице.
+type LinkList struct {
+	Response
+	Links         []Link `json:"links"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
@@ -0,0 +1,5 @@
+// Package shortenerv1 - сгенерированный код gRPC API сервиса
+// из api/proto/shortener/v1/shortener.proto.
+package shortenerv1
+
+//go:generate protoc -I ../../../../api/proto --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative shortener/v1/shortener.proto
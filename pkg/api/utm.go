@@ -0,0 +1,58 @@
+package api
+
+// UTM - набор UTM-меток. Пустые поля не добавляются к адресу.
+type UTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// Merge возвращает набор, в котором непустые поля over перекрывают поля u.
+func (u UTM) Merge(over UTM) UTM {
+	pick := func(base, over string) string {
+		if over != "" {
+			return over
+		}
+		return base
+	}
+
+	return UTM{
+		Source:   pick(u.Source, over.Source),
+		Medium:   pick(u.Medium, over.Medium),
+		Campaign: pick(u.Campaign, over.Campaign),
+		Term:     pick(u.Term, over.Term),
+		Content:  pick(u.Content, over.Content),
+	}
+}
+
+// Params возвращает непустые метки в виде query-параметров utm_*.
+func (u UTM) Params() map[string]string {
+	params := make(map[string]string, 5)
+	for key, value := range map[string]string{
+		"utm_source":   u.Source,
+		"utm_medium":   u.Medium,
+		"utm_campaign": u.Campaign,
+		"utm_term":     u.Term,
+		"utm_content":  u.Content,
+	} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	return params
+}
+
+// UTMTemplate - именованный шаблон UTM-меток.
+type UTMTemplate struct {
+	Response
+	Name string `json:"name"`
+	UTM  UTM    `json:"utm"`
+}
+
+type UTMTemplateSaveResponse struct {
+	Response
+	Name string `json:"name"`
+}
@@ -0,0 +1,203 @@
+// Package client - Go-клиент HTTP API сервиса (/api/v1). Типы запросов
+// и ответов - те же, что у обработчиков, поэтому клиент не расходится
+// с сервером.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiPrefix = "/api/v1"
+
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+	defaultTimeout    = 30 * time.Second
+)
+
+// Options - настройки клиента. Нулевые значения заменяются значениями
+// по умолчанию.
+type Options struct {
+	// Учетные данные basic auth, как в конфиге сервера
+	User     string
+	Password string
+
+	// По умолчанию - http.Client с таймаутом 30 секунд
+	HTTPClient *http.Client
+
+	// Сколько раз повторять запрос после ответа 429 или 5xx. По умолчанию 3,
+	// отрицательное значение отключает повторы
+	MaxRetries int
+	// Пауза перед повтором удваивается с каждой попыткой от MinBackoff
+	// до MaxBackoff. Retry-After сервера важнее, но тоже не больше MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+type Client struct {
+	baseURL string
+	opts    Options
+}
+
+// New создает клиент сервиса по адресу baseURL, например https://sho.rt.
+func New(baseURL string, opts Options) *Client {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.MinBackoff)
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		opts:    opts,
+	}
+}
+
+// call - один вызов API.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	// Тело запроса, кодируется в JSON; nil - без тела
+	body any
+	// Куда декодировать JSON успешного ответа; nil - ответ не нужен
+	out any
+	// Чем считать ответ с кодом not_found
+	notFound error
+}
+
+// do выполняет вызов и возвращает тело успешного ответа.
+// Ответы с ошибкой превращаются в *Error.
+func (c *Client) do(ctx context.Context, cl call) ([]byte, error) {
+	var body []byte
+	if cl.body != nil {
+		var err error
+		body, err = json.Marshal(cl.body)
+		if err != nil {
+			return nil, fmt.Errorf("client: encode request: %w", err)
+		}
+	}
+
+	u := c.baseURL + apiPrefix + cl.path
+	if len(cl.query) > 0 {
+		u += "?" + cl.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, data, err := c.send(ctx, cl.method, u, body)
+
+		if attempt < c.opts.MaxRetries && retryable(cl.method, res, err) {
+			if waitErr := sleep(ctx, c.backoff(attempt, res)); waitErr != nil {
+				return nil, waitErr
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("client: %s %s: %w", cl.method, cl.path, err)
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return nil, newError(res, data, cl.notFound)
+		}
+
+		if cl.out != nil {
+			if err := json.Unmarshal(data, cl.out); err != nil {
+				return nil, fmt.Errorf("client: decode response: %w", err)
+			}
+		}
+
+		return data, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u string, body []byte) (*http.Response, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.opts.User != "" {
+		req.SetBasicAuth(c.opts.User, c.opts.Password)
+	}
+
+	res, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res, data, nil
+}
+
+// retryable решает, повторять ли запрос. 429 сервер не обработал, его
+// повторяем всегда. Сетевые ошибки и 5xx повторяем только для идемпотентных
+// методов: POST мог успеть создать ссылку, и повтор создал бы вторую.
+func retryable(method string, res *http.Response, err error) bool {
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if method == http.MethodPost {
+		return false
+	}
+
+	return err != nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.opts.MaxBackoff)
+		}
+	}
+
+	d := c.opts.MinBackoff << attempt
+	if d <= 0 || d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+
+	// Разброс, чтобы клиенты не повторяли запросы одновременно
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Tbits007/url-shortener/internal/config"
+	"github.com/Tbits007/url-shortener/internal/http-server/router"
+	resp "github.com/Tbits007/url-shortener/internal/lib/api/response"
+	"github.com/Tbits007/url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"github.com/Tbits007/url-shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Links(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	c := newTestClient(t, mockStorage, nil)
+	ctx := context.Background()
+
+	link := storage.Link{Alias: "docs", URL: "https://example.com/docs", Title: "Docs", Owner: "admin"}
+
+	mockStorage.On("SaveURL", mock.Anything, mock.MatchedBy(func(l storage.Link) bool {
+		return l.Alias == "docs" && l.Owner == "admin"
+	})).Return(nil).Once()
+	created, err := c.Create(ctx, CreateRequest{URL: link.URL, Alias: "docs", Title: "Docs"})
+	require.NoError(t, err)
+	assert.Equal(t, "docs", created.Alias)
+
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+	got, err := c.Get(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, link.URL, got.URL)
+	assert.Equal(t, "admin", got.Owner)
+
+	// Поля вне списка не отправляются и остаются как были
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+	mockStorage.On("UpdateURL", mock.Anything, mock.MatchedBy(func(l storage.Link) bool {
+		return l.Title == "" && l.URL == link.URL && l.MaxClicks == 10
+	})).Return(nil).Once()
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{Alias: "docs", URL: link.URL, MaxClicks: 10}, nil).Once()
+	updated, err := c.Update(ctx, "docs", CreateRequest{URL: "https://ignored.example.com", MaxClicks: 10}, "title", "max_clicks")
+	require.NoError(t, err)
+	assert.Equal(t, 10, updated.MaxClicks)
+
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+	mockStorage.On("GetClickStats", mock.Anything, "docs").Return(storage.ClickStats{Total: 7}, nil).Once()
+	st, err := c.Stats(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, 7, st.Clicks)
+
+	mockStorage.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+	png, err := c.QR(ctx, "docs", QROptions{Size: 128})
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG", string(png[:4]))
+
+	mockStorage.On("DeleteURL", mock.Anything, "docs").Return(nil).Once()
+	require.NoError(t, c.Delete(ctx, "docs"))
+}
+
+func TestClient_List(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	c := newTestClient(t, mockStorage, nil)
+	ctx := context.Background()
+
+	links := []storage.Link{
+		{Alias: "a", URL: "https://example.com/a", Owner: "admin"},
+		{Alias: "b", URL: "https://example.com/b", Owner: "admin"},
+		{Alias: "c", URL: "https://example.com/c", Owner: "admin"},
+	}
+
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", Limit: 3}).Return(links, nil).Once()
+	page, err := c.List(ctx, ListOptions{Owner: "admin", PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 2)
+	assert.Equal(t, "b", page.Links[1].Alias)
+	require.NotEmpty(t, page.NextPageToken)
+
+	mockStorage.On("ListURLs", mock.Anything, storage.ListFilter{Owner: "admin", After: "b", Limit: 3}).Return(links[2:], nil).Once()
+	page, err = c.List(ctx, ListOptions{Owner: "admin", PageSize: 2, PageToken: page.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, "c", page.Links[0].Alias)
+	assert.Empty(t, page.NextPageToken)
+}
+
+func TestClient_UTMTemplates(t *testing.T) {
+	mockStorage := router.NewMockStorage(t)
+	c := newTestClient(t, mockStorage, nil)
+	ctx := context.Background()
+
+	utm := UTM{Source: "newsletter", Medium: "email"}
+
+	mockStorage.On("SaveUTMTemplate", mock.Anything, "mail", utm).Return(nil).Once()
+	require.NoError(t, c.SaveUTMTemplate(ctx, "mail", utm))
+
+	mockStorage.On("GetUTMTemplate", mock.Anything, "mail").Return(utm, nil).Once()
+	tmpl, err := c.GetUTMTemplate(ctx, "mail")
+	require.NoError(t, err)
+	assert.Equal(t, utm, tmpl.UTM)
+
+	mockStorage.On("GetUTMTemplate", mock.Anything, "missing").Return(storage.UTM{}, storage.ErrUTMTemplateNotFound).Once()
+	_, err = c.GetUTMTemplate(ctx, "missing")
+	assert.ErrorIs(t, err, ErrUTMTemplateNotFound)
+}
+
+func TestClient_Errors(t *testing.T) {
+	cases := []struct {
+		name         string
+		setup        func(m *router.MockStorage)
+		call         func(c *Client) error
+		expectedErr  error
+		expectedCode string
+	}{
+		{
+			name: "alias exists",
+			setup: func(m *router.MockStorage) {
+				m.On("SaveURL", mock.Anything, mock.Anything).Return(storage.ErrURLExists).Once()
+			},
+			call: func(c *Client) error {
+				_, err := c.Create(context.Background(), CreateRequest{URL: "https://example.com", Alias: "docs"})
+				return err
+			},
+			expectedErr:  ErrURLExists,
+			expectedCode: resp.CodeAliasExists,
+		},
+		{
+			name: "url not found",
+			setup: func(m *router.MockStorage) {
+				m.On("GetURL", mock.Anything, "docs").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+			},
+			call: func(c *Client) error {
+				_, err := c.Get(context.Background(), "docs")
+				return err
+			},
+			expectedErr:  ErrURLNotFound,
+			expectedCode: resp.CodeNotFound,
+		},
+		{
+			name:  "validation failed",
+			setup: func(m *router.MockStorage) {},
+			call: func(c *Client) error {
+				_, err := c.Create(context.Background(), CreateRequest{URL: "not a url"})
+				return err
+			},
+			expectedCode: resp.CodeValidationFailed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := router.NewMockStorage(t)
+			tc.setup(mockStorage)
+
+			err := tc.call(newTestClient(t, mockStorage, nil))
+
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tc.expectedCode, apiErr.Code)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+		})
+	}
+
+	t.Run("field errors", func(t *testing.T) {
+		_, err := newTestClient(t, router.NewMockStorage(t), nil).
+			Create(context.Background(), CreateRequest{URL: "not a url"})
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Len(t, apiErr.Details, 1)
+		assert.Equal(t, "url", apiErr.Details[0].Field)
+	})
+
+	t.Run("wrong credentials", func(t *testing.T) {
+		c := newTestClient(t, router.NewMockStorage(t), nil)
+		c.opts.Password = "wrong"
+
+		_, err := c.Get(context.Background(), "docs")
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	link := storage.Link{Alias: "docs", URL: "https://example.com/docs"}
+	timeout := fmt.Errorf("storage: %w", context.DeadlineExceeded)
+
+	t.Run("5xx is retried for idempotent methods", func(t *testing.T) {
+		mockStorage := router.NewMockStorage(t)
+		mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{}, timeout).Twice()
+		mockStorage.On("GetURL", mock.Anything, "docs").Return(link, nil).Once()
+
+		got, err := newTestClient(t, mockStorage, nil).Get(context.Background(), "docs")
+		require.NoError(t, err)
+		assert.Equal(t, link.URL, got.URL)
+	})
+
+	t.Run("5xx is not retried for create", func(t *testing.T) {
+		mockStorage := router.NewMockStorage(t)
+		mockStorage.On("SaveURL", mock.Anything, mock.Anything).Return(timeout).Once()
+
+		_, err := newTestClient(t, mockStorage, nil).
+			Create(context.Background(), CreateRequest{URL: link.URL, Alias: "docs"})
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	})
+
+	t.Run("429 is retried for create", func(t *testing.T) {
+		mockStorage := router.NewMockStorage(t)
+		mockStorage.On("SaveURL", mock.Anything, mock.Anything).Return(nil).Once()
+
+		var limited atomic.Bool
+		wrap := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !limited.Swap(true) {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+
+		_, err := newTestClient(t, mockStorage, wrap).
+			Create(context.Background(), CreateRequest{URL: link.URL, Alias: "docs"})
+		require.NoError(t, err)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		mockStorage := router.NewMockStorage(t)
+		mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{}, timeout).Times(defaultMaxRetries + 1)
+
+		_, err := newTestClient(t, mockStorage, nil).Get(context.Background(), "docs")
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, resp.CodeStorageTimeout, apiErr.Code)
+	})
+
+	t.Run("context cancels the wait", func(t *testing.T) {
+		mockStorage := router.NewMockStorage(t)
+		mockStorage.On("GetURL", mock.Anything, "docs").Return(storage.Link{}, timeout).Once()
+
+		c := newTestClient(t, mockStorage, nil)
+		c.opts.MinBackoff = time.Hour
+		c.opts.MaxBackoff = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.Get(ctx, "docs")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+// newTestClient поднимает настоящий роутер сервиса поверх мока хранилища.
+func newTestClient(t *testing.T, storage router.Storage, wrap func(http.Handler) http.Handler) *Client {
+	t.Helper()
+
+	cfg := &config.Config{
+		HTTPServer: config.HTTPServer{
+			User:     "admin",
+			Password: "secret",
+		},
+	}
+
+	var handler http.Handler = router.New(slogdiscard.NewDiscardLogger(), cfg, router.Deps{
+		Storage:      storage,
+		ShuttingDown: new(atomic.Bool),
+	})
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return New(srv.URL, Options{
+		User:       "admin",
+		Password:   "secret",
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+}
+
+// Клиент подключают в чужие модули: он не должен тянуть за собой
+// пакеты сервера, метрики и драйверы базы.
+func TestClientDeps(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool is not available")
+	}
+
+	out, err := exec.Command(gobin, "list", "-deps", ".").Output()
+	require.NoError(t, err)
+
+	for _, pkg := range strings.Fields(string(out)) {
+		assert.False(t, strings.HasPrefix(pkg, "github.com/Tbits007/url-shortener/internal/"), "client depends on %s", pkg)
+		assert.NotContains(t, pkg, "prometheus", "client depends on %s", pkg)
+		assert.NotContains(t, pkg, "lib/pq", "client depends on %s", pkg)
+	}
+}
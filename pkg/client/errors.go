@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Tbits007/url-shortener/pkg/api"
+)
+
+// Те же значения, что у хранилища, поэтому errors.Is работает с обоими.
+var (
+	ErrURLExists           = api.ErrURLExists
+	ErrURLNotFound         = api.ErrURLNotFound
+	ErrUTMTemplateNotFound = api.ErrUTMTemplateNotFound
+)
+
+// Error - ответ API с ошибкой. Code - машиночитаемый код из ответа
+// (alias_exists, validation_failed, ...), Details - ошибки по полям.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    []FieldError
+
+	sentinel error
+}
+
+func newError(res *http.Response, data []byte, notFound error) *Error {
+	var body api.Response
+	// Не JSON (например, ответ прокси) - остается только статус
+	_ = json.Unmarshal(data, &body)
+
+	e := &Error{
+		StatusCode: res.StatusCode,
+		Code:       body.Code,
+		Message:    body.Error,
+		Details:    body.Details,
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+
+	switch e.Code {
+	case api.CodeAliasExists:
+		e.sentinel = ErrURLExists
+	case api.CodeNotFound:
+		e.sentinel = notFound
+	case api.CodeUTMTemplateNotFound:
+		e.sentinel = ErrUTMTemplateNotFound
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("client: %d: %s", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("client: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap дает проверять ошибки через errors.Is(err, client.ErrURLNotFound).
+func (e *Error) Unwrap() error {
+	return e.sentinel
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Tbits007/url-shortener/pkg/api"
+)
+
+// Типы запросов и ответов API. Описаны в pkg/api, который разделяют
+// клиент и сервер.
+type (
+	CreateRequest  = api.CreateRequest
+	CreateResponse = api.CreateResponse
+	Link           = api.Link
+	LinkList       = api.LinkList
+	Stats          = api.Stats
+	VariantStats   = api.VariantStats
+	UTMTemplate    = api.UTMTemplate
+	FieldError     = api.FieldError
+
+	UTM        = api.UTM
+	TargetRule = api.TargetRule
+	Variant    = api.Variant
+	OpenGraph  = api.OpenGraph
+)
+
+// ListOptions - фильтр и страница списка ссылок. Нулевые значения - значения сервера.
+type ListOptions struct {
+	// Только ссылки этого владельца
+	Owner    string
+	PageSize int
+	// NextPageToken предыдущей страницы; пусто - первая страница
+	PageToken string
+}
+
+// QROptions - параметры картинки QR-кода. Нулевые значения - значения сервера.
+type QROptions struct {
+	// png или svg
+	Format string
+	Size   int
+	// Уровень коррекции ошибок: L, M, Q или H
+	Level  string
+	Margin *int
+	// Цвета в hex, например 000000
+	Foreground string
+	Background string
+}
+
+// Create создает ссылку. Пустой Alias - алиас сгенерирует сервер.
+func (c *Client) Create(ctx context.Context, req CreateRequest) (CreateResponse, error) {
+	var res CreateResponse
+	_, err := c.do(ctx, call{
+		method:   http.MethodPost,
+		path:     "/links",
+		body:     req,
+		out:      &res,
+		notFound: ErrURLNotFound,
+	})
+
+	return res, err
+}
+
+func (c *Client) Get(ctx context.Context, alias string) (Link, error) {
+	var res Link
+	_, err := c.do(ctx, call{
+		method:   http.MethodGet,
+		path:     linkPath(alias),
+		out:      &res,
+		notFound: ErrURLNotFound,
+	})
+
+	return res, err
+}
+
+// List возвращает страницу ссылок, упорядоченных по алиасу. Следующую
+// страницу запрашивают с PageToken из NextPageToken ответа.
+func (c *Client) List(ctx context.Context, opts ListOptions) (LinkList, error) {
+	query := url.Values{}
+	if opts.Owner != "" {
+		query.Set("owner", opts.Owner)
+	}
+	if opts.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(opts.PageSize))
+	}
+	if opts.PageToken != "" {
+		query.Set("page_token", opts.PageToken)
+	}
+
+	var res LinkList
+	_, err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/links",
+		query:  query,
+		out:    &res,
+	})
+
+	return res, err
+}
+
+// Update меняет поля ссылки, перечисленные в fields по именам JSON
+// (title, utm, targeting, ...), значениями из req. Поле из списка,
+// пустое в req, сбрасывается; остальные поля ссылки не меняются.
+// Объекты utm и og, как и в PATCH, сливаются с текущими по полям.
+func (c *Client) Update(ctx context.Context, alias string, req CreateRequest, fields ...string) (Link, error) {
+	if len(fields) == 0 {
+		return Link{}, fmt.Errorf("client: update %s: no fields to update", alias)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Link{}, fmt.Errorf("client: encode request: %w", err)
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return Link{}, fmt.Errorf("client: encode request: %w", err)
+	}
+
+	body := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			// Пустое поле опущено из-за omitempty, а null строку или число
+			// на сервере не сбросит: отправляем нулевое значение явно
+			value, ok = zeroValues[field]
+		}
+		if !ok {
+			return Link{}, fmt.Errorf("client: update %s: unknown field %q", alias, field)
+		}
+		body[field] = value
+	}
+
+	var res Link
+	_, err = c.do(ctx, call{
+		method:   http.MethodPatch,
+		path:     linkPath(alias),
+		body:     body,
+		out:      &res,
+		notFound: ErrURLNotFound,
+	})
+
+	return res, err
+}
+
+func (c *Client) Delete(ctx context.Context, alias string) error {
+	_, err := c.do(ctx, call{
+		method:   http.MethodDelete,
+		path:     linkPath(alias),
+		notFound: ErrURLNotFound,
+	})
+
+	return err
+}
+
+func (c *Client) Stats(ctx context.Context, alias string) (Stats, error) {
+	var res Stats
+	_, err := c.do(ctx, call{
+		method:   http.MethodGet,
+		path:     linkPath(alias) + "/stats",
+		out:      &res,
+		notFound: ErrURLNotFound,
+	})
+
+	return res, err
+}
+
+// QR возвращает картинку QR-кода короткой ссылки.
+func (c *Client) QR(ctx context.Context, alias string, opts QROptions) ([]byte, error) {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("format", opts.Format)
+	set("ecc", opts.Level)
+	set("fg", opts.Foreground)
+	set("bg", opts.Background)
+	if opts.Size > 0 {
+		query.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.Margin != nil {
+		query.Set("margin", strconv.Itoa(*opts.Margin))
+	}
+
+	return c.do(ctx, call{
+		method:   http.MethodGet,
+		path:     linkPath(alias) + "/qr",
+		query:    query,
+		notFound: ErrURLNotFound,
+	})
+}
+
+// SaveUTMTemplate создает или заменяет шаблон UTM-меток.
+func (c *Client) SaveUTMTemplate(ctx context.Context, name string, utm UTM) error {
+	_, err := c.do(ctx, call{
+		method:   http.MethodPut,
+		path:     utmTemplatePath(name),
+		body:     utm,
+		notFound: ErrUTMTemplateNotFound,
+	})
+
+	return err
+}
+
+func (c *Client) GetUTMTemplate(ctx context.Context, name string) (UTMTemplate, error) {
+	var res UTMTemplate
+	_, err := c.do(ctx, call{
+		method:   http.MethodGet,
+		path:     utmTemplatePath(name),
+		out:      &res,
+		notFound: ErrUTMTemplateNotFound,
+	})
+
+	return res, err
+}
+
+// zeroValues - нулевые значения полей CreateRequest в JSON по именам полей.
+var zeroValues = func() map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage)
+
+	typ := reflect.TypeOf(CreateRequest{})
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value, err := json.Marshal(reflect.Zero(field.Type).Interface())
+		if err != nil {
+			panic(err)
+		}
+		values[name] = value
+	}
+
+	return values
+}()
+
+func linkPath(alias string) string {
+	return "/links/" + url.PathEscape(alias)
+}
+
+func utmTemplatePath(name string) string {
+	return "/utm-templates/" + url.PathEscape(name)
+}